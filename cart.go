@@ -0,0 +1,191 @@
+package wayforpay
+
+import (
+	"strconv"
+)
+
+// CartItem is a product line of a Cart.
+type CartItem struct {
+	Name      string
+	UnitPrice Amount
+	Quantity  int
+	// SKU is the merchant's product code. It is not sent to WayForPay.
+	SKU string
+}
+
+// Total returns the unit price multiplied by the quantity.
+func (i CartItem) Total() Amount {
+	return i.UnitPrice.Mul(int64(i.Quantity))
+}
+
+// Discount lowers the cart total either by a fixed Amount or by Percent of the
+// subtotal. Discounts are not sent as product lines; they only reduce the amount.
+type Discount struct {
+	Name    string
+	Amount  Amount
+	Percent int
+}
+
+// Cart builds the product lines of an invoice, a purchase or a charge and
+// computes the amount to pay.
+//
+// Line totals are exact. A percent discount is taken from the subtotal of all
+// items and rounded half away from zero to minor units.
+type Cart struct {
+	Items     []CartItem
+	Discounts []Discount
+}
+
+// NewCart returns an empty Cart.
+func NewCart() *Cart {
+	return &Cart{}
+}
+
+// Add adds quantity units of a product.
+func (c *Cart) Add(name string, unitPrice Amount, quantity int) *Cart {
+	return c.AddItem(CartItem{Name: name, UnitPrice: unitPrice, Quantity: quantity})
+}
+
+// AddItem adds a product line.
+func (c *Cart) AddItem(item CartItem) *Cart {
+	c.Items = append(c.Items, item)
+	return c
+}
+
+// AddDiscount lowers the total by amount.
+func (c *Cart) AddDiscount(name string, amount Amount) *Cart {
+	c.Discounts = append(c.Discounts, Discount{Name: name, Amount: amount})
+	return c
+}
+
+// AddPercentDiscount lowers the total by percent of the subtotal.
+func (c *Cart) AddPercentDiscount(name string, percent int) *Cart {
+	c.Discounts = append(c.Discounts, Discount{Name: name, Percent: percent})
+	return c
+}
+
+// Subtotal returns the sum of all line totals.
+func (c *Cart) Subtotal() Amount {
+	var total Amount
+	for _, item := range c.Items {
+		total = total.Add(item.Total())
+	}
+	return total
+}
+
+// DiscountTotal returns the sum of all discounts.
+func (c *Cart) DiscountTotal() Amount {
+	subtotal := c.Subtotal().Minor()
+	var total Amount
+	for _, d := range c.Discounts {
+		if d.Percent == 0 {
+			total = total.Add(d.Amount)
+			continue
+		}
+		off := subtotal * int64(d.Percent)
+		if off >= 0 {
+			off += 50
+		} else {
+			off -= 50
+		}
+		total = total.Add(NewAmount(off / 100))
+	}
+	return total
+}
+
+// Total returns the amount to pay: the subtotal less all discounts.
+func (c *Cart) Total() Amount {
+	return c.Subtotal().Sub(c.DiscountTotal())
+}
+
+// Validate checks every line and discount and that the total is positive.
+func (c *Cart) Validate() error {
+	if len(c.Items) == 0 {
+		return ErrEmptyCart
+	}
+	for _, item := range c.Items {
+		if item.Name == "" || item.UnitPrice.Minor() <= 0 || item.Quantity <= 0 {
+			return ErrInvalidCartItem
+		}
+	}
+	for _, d := range c.Discounts {
+		if d.Amount.Minor() < 0 || d.Percent < 0 || d.Percent > 100 || (d.Percent != 0 && !d.Amount.IsZero()) {
+			return ErrInvalidDiscount
+		}
+	}
+	if c.Total().Minor() <= 0 {
+		return ErrInvalidDiscount
+	}
+	return nil
+}
+
+// check validates the cart and the amount declared by the request.
+func (c *Cart) check(amount Amount) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if amount != c.Total() {
+		return ErrCartAmountMismatch
+	}
+	return nil
+}
+
+// products returns the productName, productPrice and productCount lines.
+func (c *Cart) products() (names []string, prices []Amount, counts []int) {
+	for _, item := range c.Items {
+		names = append(names, item.Name)
+		prices = append(prices, item.UnitPrice)
+		counts = append(counts, item.Quantity)
+	}
+	return names, prices, counts
+}
+
+// productFields returns the product lines in the order they are signed:
+// every productName, then every productCount, then every productPrice.
+func productFields(names []string, prices []Amount, counts []int) []string {
+	data := append([]string(nil), names...)
+	for _, count := range counts {
+		data = append(data, strconv.Itoa(count))
+	}
+	for _, price := range prices {
+		data = append(data, price.String())
+	}
+	return data
+}
+
+// checkProducts validates the product lines of a request. Lines set by a cart
+// must match amount as the cart total, discounts included; lines added one by
+// one must add up to amount.
+func checkProducts(amount Amount, cart *Cart, names []string, prices []Amount, counts []int) error {
+	if cart != nil {
+		if err := cart.check(amount); err != nil {
+			return err
+		}
+	}
+	if len(names) == 0 {
+		return ErrProductNameRequired
+	}
+	if len(prices) == 0 {
+		return ErrProductPriceRequired
+	}
+	if len(counts) == 0 {
+		return ErrProductCountRequired
+	}
+	if len(prices) != len(names) || len(counts) != len(names) {
+		return ErrProductLinesMismatch
+	}
+	if cart != nil {
+		return nil
+	}
+	var total Amount
+	for i := range names {
+		if prices[i].Minor() <= 0 || counts[i] <= 0 {
+			return ErrInvalidProduct
+		}
+		total = total.Add(prices[i].Mul(int64(counts[i])))
+	}
+	if total != amount {
+		return ErrProductAmountMismatch
+	}
+	return nil
+}
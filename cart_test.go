@@ -0,0 +1,137 @@
+package wayforpay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCart(t *testing.T) {
+	cart := wfp.NewCart().
+		Add("Coffee", wfp.MustParseAmount("45.50"), 3).
+		AddItem(wfp.CartItem{Name: "Croissant", UnitPrice: wfp.MustParseAmount("33.33"), Quantity: 1, SKU: "CR-1"}).
+		AddPercentDiscount("Loyalty", 5).
+		AddDiscount("Coupon", wfp.MustParseAmount("10"))
+
+	require.NoError(t, cart.Validate())
+	require.Equal(t, wfp.MustParseAmount("169.83"), cart.Subtotal())
+	// 5% of 169.83 is 8.4915 and rounds to 8.49.
+	require.Equal(t, wfp.MustParseAmount("18.49"), cart.DiscountTotal())
+	require.Equal(t, wfp.MustParseAmount("151.34"), cart.Total())
+
+	require.ErrorIs(t, wfp.NewCart().Validate(), wfp.ErrEmptyCart)
+	require.ErrorIs(t, wfp.NewCart().Add("Coffee", wfp.MustParseAmount("45.50"), 0).Validate(), wfp.ErrInvalidCartItem)
+	require.ErrorIs(t, wfp.NewCart().Add("Coffee", wfp.MustParseAmount("45.50"), 1).
+		AddDiscount("Too much", wfp.MustParseAmount("50")).Validate(), wfp.ErrInvalidDiscount)
+}
+
+func TestWayForPay_InvoiceCart(t *testing.T) {
+	wfpClient, srv := newTestClient(t)
+	ctx := context.Background()
+	cart := wfp.NewCart().
+		Add("Coffee", wfp.MustParseAmount("45.50"), 2).
+		AddDiscount("Coupon", wfp.MustParseAmount("1"))
+
+	orderReference := uuid.NewString()
+	invoice := wfpClient.NewCreateInvoiceRequest().
+		SetMerchantDomainName("test.com").
+		SetOrderReference(orderReference).
+		SetOrderDate(time.Now()).
+		SetCurrency("UAH").
+		AddProduct("ignored", wfp.MustParseAmount("1"), 1).
+		SetCart(cart)
+	_, err := wfpClient.CreateInvoiceContext(ctx, invoice)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Coffee"}, invoice.ProductName)
+	require.Equal(t, []wfp.Amount{wfp.MustParseAmount("45.5")}, invoice.ProductPrice)
+	require.Equal(t, []int{2}, invoice.ProductCount)
+	order, ok := srv.Order(orderReference)
+	require.True(t, ok)
+	require.Equal(t, 90.0, order.Amount)
+
+	_, err = wfpClient.CreateInvoiceContext(ctx, wfpClient.NewCreateInvoiceRequest().
+		SetMerchantDomainName("test.com").
+		SetOrderReference(uuid.NewString()).
+		SetOrderDate(time.Now()).
+		SetCurrency("UAH").
+		SetCart(cart).
+		SetAmount(wfp.MustParseAmount("91")))
+	require.ErrorIs(t, err, wfp.ErrCartAmountMismatch)
+}
+
+func TestWayForPay_ProductTotal(t *testing.T) {
+	wfpClient, err := wfp.NewClient(nil, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+	coffee := wfp.MustParseAmount("45.50")
+
+	cases := []struct {
+		name     string
+		amount   string
+		products func(*wfp.CreateInvoiceRequest) *wfp.CreateInvoiceRequest
+		wantErr  error
+	}{
+		{
+			name:   "lines add up",
+			amount: "124.5",
+			products: func(r *wfp.CreateInvoiceRequest) *wfp.CreateInvoiceRequest {
+				return r.AddProduct("Coffee", coffee, 2).AddProduct("Croissant", wfp.MustParseAmount("33.5"), 1)
+			},
+		},
+		{
+			name:   "lines do not add up",
+			amount: "100",
+			products: func(r *wfp.CreateInvoiceRequest) *wfp.CreateInvoiceRequest {
+				return r.AddProduct("Coffee", coffee, 2)
+			},
+			wantErr: wfp.ErrProductAmountMismatch,
+		},
+		{
+			name:   "zero count",
+			amount: "45.5",
+			products: func(r *wfp.CreateInvoiceRequest) *wfp.CreateInvoiceRequest {
+				return r.AddProduct("Coffee", coffee, 0)
+			},
+			wantErr: wfp.ErrInvalidProduct,
+		},
+		{
+			name:   "lines of different length",
+			amount: "45.5",
+			products: func(r *wfp.CreateInvoiceRequest) *wfp.CreateInvoiceRequest {
+				r.AddProduct("Coffee", coffee, 1)
+				r.ProductCount = append(r.ProductCount, 1)
+				return r
+			},
+			wantErr: wfp.ErrProductLinesMismatch,
+		},
+		{
+			name:   "cart with a discount",
+			amount: "81",
+			products: func(r *wfp.CreateInvoiceRequest) *wfp.CreateInvoiceRequest {
+				return r.SetCart(wfp.NewCart().Add("Coffee", coffee, 2).AddDiscount("Coupon", wfp.MustParseAmount("10")))
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := tt.products(wfpClient.NewCreateInvoiceRequest().
+				SetMerchantDomainName("test.com").
+				SetOrderReference("AAA").
+				SetOrderDate(time.Unix(1700000000, 0)).
+				SetMoney(wfp.NewMoney(wfp.MustParseAmount(tt.amount), "UAH")))
+
+			purchase := wfpClient.NewPurchaseRequestFromInvoice(invoice)
+			_, purchaseErr := wfpClient.PurchaseForm(purchase)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, purchaseErr, tt.wantErr)
+				_, err := wfpClient.CreateInvoiceContext(context.Background(), invoice)
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, purchaseErr)
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package wayforpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ReasonCodeWait3DS is returned by CHARGE when the card holder has to pass 3-D Secure.
+const ReasonCodeWait3DS = 5100
+
+// ChargeRequest charges a card directly (host-to-host). Sending card data
+// requires the merchant to be PCI DSS compliant.
+type ChargeRequest struct {
+	TransactionType               string        `json:"transactionType"`
+	MerchantAccount               string        `json:"merchantAccount"`
+	MerchantAuthType              SignatureMode `json:"merchantAuthType,omitempty"`
+	MerchantDomainName            string        `json:"merchantDomainName"`
+	MerchantTransactionType       string        `json:"merchantTransactionType,omitempty"`
+	MerchantTransactionSecureType string        `json:"merchantTransactionSecureType"`
+	MerchantSignature             string        `json:"merchantSignature"`
+	ApiVersion                    int           `json:"apiVersion"`
+	OrderReference                string        `json:"orderReference"`
+	OrderDate                     int64         `json:"orderDate"`
+	Money
+	Card            string   `json:"card,omitempty"`
+	ExpMonth        string   `json:"expMonth,omitempty"`
+	ExpYear         string   `json:"expYear,omitempty"`
+	CardCvv         string   `json:"cardCvv,omitempty"`
+	CardHolder      string   `json:"cardHolder,omitempty"`
+	RecToken        string   `json:"recToken,omitempty"`
+	ProductName     []string `json:"productName"`
+	ProductPrice    []Amount `json:"productPrice"`
+	ProductCount    []int    `json:"productCount"`
+	Cart            *Cart    `json:"-"`
+	ClientFirstName string   `json:"clientFirstName,omitempty"`
+	ClientLastName  string   `json:"clientLastName,omitempty"`
+	ClientCountry   string   `json:"clientCountry,omitempty"`
+	ClientEmail     string   `json:"clientEmail,omitempty"`
+	ClientPhone     string   `json:"clientPhone,omitempty"`
+	ClientIPAddress string   `json:"clientIpAddress"`
+}
+
+// NewChargeRequest returns a new ChargeRequest.
+func (w *WayForPay) NewChargeRequest() *ChargeRequest {
+	return &ChargeRequest{
+		TransactionType:               "CHARGE",
+		MerchantAccount:               w.merchantLogin,
+		MerchantAuthType:              SignatureModeSimple,
+		MerchantTransactionSecureType: "AUTO",
+		ApiVersion:                    w.apiVersion,
+	}
+}
+
+// NewRecTokenChargeRequest returns a ChargeRequest that charges the card saved
+// behind recToken instead of card data. Use it with ChargeByToken.
+func (w *WayForPay) NewRecTokenChargeRequest(recToken string) *ChargeRequest {
+	c := w.NewChargeRequest()
+	c.MerchantTransactionSecureType = "NON3DS"
+	c.RecToken = recToken
+	return c
+}
+
+func (c *ChargeRequest) SetMerchantDomainName(merchantDomainName string) *ChargeRequest {
+	c.MerchantDomainName = merchantDomainName
+	return c
+}
+
+// SetMerchantTransactionType sets the merchant transaction type.
+// Possible values: AUTO, AUTH, SALE
+func (c *ChargeRequest) SetMerchantTransactionType(merchantTransactionType string) *ChargeRequest {
+	c.MerchantTransactionType = merchantTransactionType
+	return c
+}
+
+func (c *ChargeRequest) SetOrderReference(orderReference string) *ChargeRequest {
+	c.OrderReference = orderReference
+	return c
+}
+
+func (c *ChargeRequest) SetOrderDate(orderDate time.Time) *ChargeRequest {
+	c.OrderDate = orderDate.Unix()
+	return c
+}
+
+func (c *ChargeRequest) SetAmount(amount Amount) *ChargeRequest {
+	c.Amount = amount
+	return c
+}
+
+// SetMoney sets the amount and the currency.
+func (c *ChargeRequest) SetMoney(money Money) *ChargeRequest {
+	c.Money = money
+	return c
+}
+
+func (c *ChargeRequest) SetCurrency(currency string) *ChargeRequest {
+	c.Currency = currency
+	return c
+}
+
+// SetCard sets the card data. expMonth and expYear are formatted as MM and YYYY.
+func (c *ChargeRequest) SetCard(card string, expMonth, expYear int, cvv, holder string) *ChargeRequest {
+	c.Card = card
+	c.ExpMonth = padMonth(expMonth)
+	c.ExpYear = strconv.Itoa(expYear)
+	c.CardCvv = cvv
+	c.CardHolder = holder
+	return c
+}
+
+// SetRecToken sets the card token returned by a previous payment.
+func (c *ChargeRequest) SetRecToken(recToken string) *ChargeRequest {
+	c.RecToken = recToken
+	return c
+}
+
+// AddProduct adds a product line of count units at price each. Unless a cart
+// is set, the lines must add up to the amount.
+func (c *ChargeRequest) AddProduct(name string, price Amount, count int) *ChargeRequest {
+	c.ProductName = append(c.ProductName, name)
+	c.ProductPrice = append(c.ProductPrice, price)
+	c.ProductCount = append(c.ProductCount, count)
+	return c
+}
+
+// SetCart sets the product lines and the amount from cart. The cart replaces the
+// lines added with AddProduct and must still match the amount when sent.
+func (c *ChargeRequest) SetCart(cart *Cart) *ChargeRequest {
+	c.Cart = cart
+	c.Amount = cart.Total()
+	return c
+}
+
+func (c *ChargeRequest) SetClientFirstName(clientFirstName string) *ChargeRequest {
+	c.ClientFirstName = clientFirstName
+	return c
+}
+
+func (c *ChargeRequest) SetClientLastName(clientLastName string) *ChargeRequest {
+	c.ClientLastName = clientLastName
+	return c
+}
+
+func (c *ChargeRequest) SetClientCountry(clientCountry string) *ChargeRequest {
+	c.ClientCountry = clientCountry
+	return c
+}
+
+func (c *ChargeRequest) SetClientEmail(clientEmail string) *ChargeRequest {
+	c.ClientEmail = clientEmail
+	return c
+}
+
+func (c *ChargeRequest) SetClientPhone(clientPhone string) *ChargeRequest {
+	c.ClientPhone = clientPhone
+	return c
+}
+
+// SetClientIPAddress sets the IP address of the card holder.
+func (c *ChargeRequest) SetClientIPAddress(clientIPAddress string) *ChargeRequest {
+	c.ClientIPAddress = clientIPAddress
+	return c
+}
+
+func padMonth(month int) string {
+	if month < 10 {
+		return "0" + strconv.Itoa(month)
+	}
+	return strconv.Itoa(month)
+}
+
+func (c *ChargeRequest) validate() error {
+	if c.TransactionType == "" {
+		return ErrTransactionTypeRequired
+	}
+	if c.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if c.MerchantDomainName == "" {
+		return ErrMerchantDomainNameRequired
+	}
+	if c.MerchantSignature == "" {
+		return ErrMerchantSignatureRequired
+	}
+	if c.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	if c.OrderDate == 0 {
+		return ErrOrderDateRequired
+	}
+	if err := c.Money.check(); err != nil {
+		return err
+	}
+	if c.RecToken == "" && (c.Card == "" || c.ExpMonth == "" || c.ExpYear == "" || c.CardCvv == "") {
+		return ErrCardRequired
+	}
+	if err := checkProducts(c.Amount, c.Cart, c.ProductName, c.ProductPrice, c.ProductCount); err != nil {
+		return err
+	}
+	if c.ClientIPAddress == "" {
+		return ErrClientIPAddressRequired
+	}
+	return nil
+}
+
+func (c *ChargeRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (c *ChargeRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (c *ChargeRequest) transaction() (transactionType, orderReference string) {
+	return c.TransactionType, c.OrderReference
+}
+
+func (c *ChargeRequest) signatureFields() []string {
+	if c.Cart != nil {
+		c.ProductName, c.ProductPrice, c.ProductCount = c.Cart.products()
+	}
+	data := []string{
+		c.MerchantAccount,
+		c.MerchantDomainName,
+		c.OrderReference,
+		strconv.FormatInt(c.OrderDate, 10),
+		c.Amount.String(),
+		c.Currency,
+	}
+
+	data = append(data, productFields(c.ProductName, c.ProductPrice, c.ProductCount)...)
+	return data
+}
+
+func (c *ChargeRequest) setSignature(signature string) {
+	c.MerchantSignature = signature
+}
+
+// Charge charges the card. When the card holder has to pass 3-D Secure the
+// response has Requires3DS set: redirect the customer with ACSForm and finish
+// the payment with Complete3DS once the ACS posts back to the term url.
+func (w *WayForPay) Charge(ctx context.Context, request *ChargeRequest) (*ChargeResponse, error) {
+	return Do[*ChargeRequest, ChargeResponse](ctx, w, request)
+}
+
+// recTokenErrors maps the reason codes of a declined token charge that mean
+// the saved card cannot be charged again to token-specific errors. The generic
+// sentinel still matches, e.g. 1105 is both ErrRecTokenCardBlocked and
+// ErrInvalidCard.
+//
+// WayForPay has no dedicated code for a blocked card: the issuer's refusal of
+// a blocked, closed or lost card comes back as 1105 Invalid Card. 1118
+// Merchant Restriction and 1135 Card limits failed are not caused by the card
+// and keep their generic sentinel only.
+var recTokenErrors = map[int]error{
+	1103: ErrRecTokenExpired,
+	1105: ErrRecTokenCardBlocked,
+	1116: ErrRecTokenNotFound,
+}
+
+// ChargeByToken charges the card saved behind request.RecToken, with the same
+// signing rules as Charge. Besides the reason-code sentinels, failures caused by
+// the token also match ErrRecTokenExpired, ErrRecTokenCardBlocked or
+// ErrRecTokenNotFound.
+func (w *WayForPay) ChargeByToken(ctx context.Context, request *ChargeRequest) (*ChargeResponse, error) {
+	if request.RecToken == "" {
+		return nil, ErrRecTokenRequired
+	}
+	resp, err := w.Charge(ctx, request)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if tokenErr, ok := recTokenErrors[apiErr.ReasonCode]; ok {
+				return nil, fmt.Errorf("%w: %w", tokenErr, err)
+			}
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Complete3DSRequest finishes a CHARGE that waits for 3-D Secure. It is not
+// signed: WayForPay authorizes it with the merchant authorization ticket and
+// finds the charge by the MD posted back by the ACS.
+type Complete3DSRequest struct {
+	TransactionType     string `json:"transactionType"`
+	ApiVersion          int    `json:"apiVersion"`
+	AuthorizationTicket string `json:"authorization_ticket"`
+	D3Md                string `json:"d3Md"`
+	D3Pares             string `json:"d3Pares"`
+}
+
+// NewComplete3DSRequest returns a Complete3DSRequest with the MD and PaRes
+// fields posted back by the ACS, authorized by the ticket set with
+// WithAuthorizationTicket.
+func (w *WayForPay) NewComplete3DSRequest(md, pares string) *Complete3DSRequest {
+	return &Complete3DSRequest{
+		TransactionType:     "COMPLETE_3DS",
+		ApiVersion:          w.apiVersion,
+		AuthorizationTicket: w.authorizationTicket,
+		D3Md:                md,
+		D3Pares:             pares,
+	}
+}
+
+func (c *Complete3DSRequest) validate() error {
+	if c.TransactionType == "" {
+		return ErrTransactionTypeRequired
+	}
+	if c.AuthorizationTicket == "" {
+		return ErrAuthorizationTicketRequired
+	}
+	if c.D3Md == "" || c.D3Pares == "" {
+		return ErrThreeDSDataRequired
+	}
+	return nil
+}
+
+func (c *Complete3DSRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (c *Complete3DSRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (c *Complete3DSRequest) transaction() (transactionType, orderReference string) {
+	return c.TransactionType, ""
+}
+
+// signatureFields returns nil: COMPLETE_3DS is authorized by the ticket.
+func (c *Complete3DSRequest) signatureFields() []string {
+	return nil
+}
+
+func (c *Complete3DSRequest) setSignature(string) {}
+
+// Complete3DS finishes a charge after the ACS posted back the MD and PaRes
+// fields to the term url passed to ACSForm.
+func (w *WayForPay) Complete3DS(ctx context.Context, md, pares string) (*ChargeResponse, error) {
+	request := w.NewComplete3DSRequest(md, pares)
+	cr, err := Do[*Complete3DSRequest, ChargeResponse](ctx, w, request)
+	if err != nil {
+		return nil, err
+	}
+	if cr.Requires3DS() {
+		return nil, withRequest(&APIError{ReasonCode: cr.ReasonCode, Reason: cr.Reason}, request.TransactionType, cr.OrderReference)
+	}
+	return cr, nil
+}
+
+// ChargeResponse is the result of a host-to-host transaction.
+type ChargeResponse struct {
+	MerchantAccount   string `json:"merchantAccount"`
+	OrderReference    string `json:"orderReference"`
+	MerchantSignature string `json:"merchantSignature"`
+	Money
+	AuthCode          string    `json:"authCode"`
+	CreatedDate       time.Time `json:"createdDate"`
+	ProcessingDate    time.Time `json:"processingDate"`
+	CardPan           string    `json:"cardPan"`
+	CardType          string    `json:"cardType"`
+	IssuerBankCountry string    `json:"issuerBankCountry"`
+	IssuerBankName    string    `json:"issuerBankName"`
+	RecToken          string    `json:"recToken"`
+	TransactionStatus string    `json:"transactionStatus"`
+	Reason            string    `json:"reason"`
+	ReasonCode        int       `json:"reasonCode"`
+	Fee               Amount    `json:"fee"`
+	PaymentSystem     string    `json:"paymentSystem"`
+	D3AcsUrl          string    `json:"d3AcsUrl"`
+	D3Md              string    `json:"d3Md"`
+	D3Pareq           string    `json:"d3Pareq"`
+}
+
+// UnmarshalJSON decodes the transaction result, accepting numbers sent as strings.
+func (c *ChargeResponse) UnmarshalJSON(data []byte) error {
+	type alias ChargeResponse
+	aux := struct {
+		*alias
+		CreatedDate    jsonTime `json:"createdDate"`
+		ProcessingDate jsonTime `json:"processingDate"`
+		ReasonCode     jsonInt  `json:"reasonCode"`
+		Fee            jsonFee  `json:"fee"`
+	}{alias: (*alias)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.CreatedDate = time.Time(aux.CreatedDate)
+	c.ProcessingDate = time.Time(aux.ProcessingDate)
+	c.ReasonCode = int(aux.ReasonCode)
+	c.Fee = Amount(aux.Fee)
+	return nil
+}
+
+// Requires3DS reports whether the card holder has to pass 3-D Secure to finish
+// the payment: the answer has ReasonCodeWait3DS and an ACS url to redirect to.
+func (c *ChargeResponse) Requires3DS() bool {
+	return c.ReasonCode == ReasonCodeWait3DS && c.D3AcsUrl != ""
+}
+
+// ACSForm returns the form that redirects the card holder to the 3-D Secure page
+// of the issuer. The ACS posts the MD and PaRes fields back to termURL.
+func (c *ChargeResponse) ACSForm(termURL string) (*Form, error) {
+	if !c.Requires3DS() {
+		return nil, ErrThreeDSNotRequired
+	}
+	return &Form{
+		Action: c.D3AcsUrl,
+		Fields: url.Values{
+			"PaReq":   {c.D3Pareq},
+			"MD":      {c.D3Md},
+			"TermUrl": {termURL},
+		},
+	}, nil
+}
+
+// Error reports a failed charge. Waiting for 3-D Secure is not an error.
+func (c *ChargeResponse) Error() error {
+	if c.Requires3DS() {
+		return nil
+	}
+	return reasonError(c.ReasonCode, c.Reason)
+}
+
+func (c *ChargeResponse) GetReasonCode() int {
+	return c.ReasonCode
+}
+
+func (c *ChargeResponse) GetReason() string {
+	return c.Reason
+}
@@ -0,0 +1,227 @@
+package wayforpay_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_Charge3DS(t *testing.T) {
+	var requests []map[string]any
+	acquirer := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			requests = append(requests, body)
+
+			var answer string
+			switch {
+			case body["transactionType"] == "COMPLETE_3DS":
+				answer = `{"orderReference":"ORD-1","amount":10,"currency":"UAH","transactionStatus":"Approved",` +
+					`"reasonCode":1100,"reason":"Ok","recToken":"tok","cardPan":"41****1111"}`
+			case body["card"] == "4111111111111112":
+				answer = `{"orderReference":"ORD-2","transactionStatus":"Declined","reasonCode":1104,"reason":"Insufficient Funds"}`
+			default:
+				answer = `{"orderReference":"ORD-1","transactionStatus":"InProcessing","reasonCode":5100,"reason":"Wait 3ds data",` +
+					`"d3AcsUrl":"https://acs.bank.example/pareq","d3Md":"md-value","d3Pareq":"pareq-value"}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(answer)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(acquirer, merchantLogin, merchantSecret, wfp.WithAuthorizationTicket("ticket"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	newCharge := func(orderReference, card string) *wfp.ChargeRequest {
+		return wfpClient.NewChargeRequest().
+			SetMerchantDomainName("test.com").
+			SetOrderReference(orderReference).
+			SetOrderDate(time.Unix(1700000000, 0)).
+			SetAmount(wfp.MustParseAmount("10")).
+			SetCurrency("UAH").
+			SetCard(card, 3, 2030, "123", "JOHN DOE").
+			SetClientIPAddress("10.0.0.1").
+			AddProduct("test", wfp.MustParseAmount("10"), 1)
+	}
+
+	resp, err := wfpClient.Charge(ctx, newCharge("ORD-1", "4111111111111111"))
+	require.NoError(t, err)
+	require.True(t, resp.Requires3DS())
+	require.Equal(t, "03", requests[0]["expMonth"])
+	require.Equal(t, sign(merchantSecret, merchantLogin, "test.com", "ORD-1", "1700000000", "10", "UAH", "test", "1", "10"), requests[0]["merchantSignature"])
+
+	form, err := resp.ACSForm("https://shop.example/3ds")
+	require.NoError(t, err)
+	require.Equal(t, "https://acs.bank.example/pareq", form.Action)
+	require.Equal(t, "pareq-value", form.Fields.Get("PaReq"))
+	require.Equal(t, "md-value", form.Fields.Get("MD"))
+	require.Equal(t, "https://shop.example/3ds", form.Fields.Get("TermUrl"))
+
+	done, err := wfpClient.Complete3DS(ctx, "md-value", "pares-value")
+	require.NoError(t, err)
+	require.Equal(t, "Approved", done.TransactionStatus)
+	require.Equal(t, "tok", done.RecToken)
+	require.Equal(t, map[string]any{
+		"transactionType":      "COMPLETE_3DS",
+		"apiVersion":           1.0,
+		"authorization_ticket": "ticket",
+		"d3Md":                 "md-value",
+		"d3Pares":              "pares-value",
+	}, requests[1])
+
+	_, err = done.ACSForm("https://shop.example/3ds")
+	require.ErrorIs(t, err, wfp.ErrThreeDSNotRequired)
+
+	_, err = wfpClient.Charge(ctx, newCharge("ORD-2", "4111111111111112"))
+	require.ErrorIs(t, err, wfp.ErrInsufficientFunds)
+
+	_, err = wfpClient.Charge(ctx, newCharge("ORD-3", "4111111111111111").SetClientIPAddress(""))
+	require.ErrorIs(t, err, wfp.ErrClientIPAddressRequired)
+}
+
+func TestWayForPay_Complete3DS(t *testing.T) {
+	cases := []struct {
+		name     string
+		ticket   string
+		md       string
+		wantErr  error
+		wantSent bool
+	}{
+		{name: "approved", ticket: "ticket", md: "md-value", wantSent: true},
+		{name: "wrong ticket", ticket: "other ticket", md: "md-value", wantErr: wfp.ErrInvalidSignature, wantSent: true},
+		{name: "wrong md", ticket: "ticket", md: "other-md", wantErr: wfp.ErrThreeDSFail, wantSent: true},
+		{name: "without a ticket", md: "md-value", wantErr: wfp.ErrAuthorizationTicketRequired},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+			defer srv.Close()
+			srv.SetAuthorizationTicket("ticket")
+			srv.AddOrder(wayforpaytest.Order{OrderReference: "ORD-1", Amount: 10, Currency: "UAH", D3Md: "md-value"})
+			wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret, wfp.WithAuthorizationTicket(tt.ticket))
+			require.NoError(t, err)
+
+			resp, err := wfpClient.Complete3DS(context.Background(), tt.md, "pares-value")
+			order, _ := srv.Order("ORD-1")
+			if tt.wantSent {
+				require.Equal(t, 1, srv.Calls("COMPLETE_3DS"))
+			} else {
+				require.Zero(t, srv.Calls("COMPLETE_3DS"))
+			}
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Equal(t, wayforpaytest.StatusInProcessing, order.Status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "ORD-1", resp.OrderReference)
+			require.Equal(t, wayforpaytest.StatusApproved, resp.TransactionStatus)
+			require.Equal(t, wayforpaytest.StatusApproved, order.Status)
+		})
+	}
+}
+
+func TestChargeResponse_Requires3DS(t *testing.T) {
+	cases := []struct {
+		name     string
+		response wfp.ChargeResponse
+		want     bool
+	}{
+		{name: "waits for 3ds", response: wfp.ChargeResponse{ReasonCode: wfp.ReasonCodeWait3DS, D3AcsUrl: "https://acs.bank.example"}, want: true},
+		{name: "approved with acs url", response: wfp.ChargeResponse{ReasonCode: wfp.ReasonCodeOk, D3AcsUrl: "https://acs.bank.example"}},
+		{name: "declined with acs url", response: wfp.ChargeResponse{ReasonCode: 1108, D3AcsUrl: "https://acs.bank.example"}},
+		{name: "waits without acs url", response: wfp.ChargeResponse{ReasonCode: wfp.ReasonCodeWait3DS}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.response.Requires3DS())
+		})
+	}
+}
+
+func TestWayForPay_ChargeByToken(t *testing.T) {
+	answers := map[string]string{
+		"ok":      `{"orderReference":"ORD-1","amount":10,"currency":"UAH","transactionStatus":"Approved","reasonCode":1100,"reason":"Ok","recToken":"ok"}`,
+		"expired": `{"orderReference":"ORD-1","transactionStatus":"Declined","reasonCode":1103,"reason":"Expired card"}`,
+		"blocked": `{"orderReference":"ORD-1","transactionStatus":"Declined","reasonCode":1105,"reason":"Invalid Card"}`,
+		"unknown": `{"orderReference":"ORD-1","transactionStatus":"Declined","reasonCode":1116,"reason":"Token not found"}`,
+		"funds":   `{"orderReference":"ORD-1","transactionStatus":"Declined","reasonCode":1104,"reason":"Insufficient Funds"}`,
+	}
+	acquirer := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			require.Equal(t, "NON3DS", body["merchantTransactionSecureType"])
+			require.Nil(t, body["card"])
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(answers[body["recToken"].(string)])),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(acquirer, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+
+	tokenErrs := []error{wfp.ErrRecTokenExpired, wfp.ErrRecTokenNotFound, wfp.ErrRecTokenCardBlocked}
+	cases := []struct {
+		recToken string
+		wantErrs []error
+		notErrs  []error
+	}{
+		{recToken: "ok"},
+		{recToken: "expired", wantErrs: []error{wfp.ErrRecTokenExpired}, notErrs: tokenErrs[1:]},
+		{recToken: "blocked", wantErrs: []error{wfp.ErrRecTokenCardBlocked, wfp.ErrInvalidCard}, notErrs: tokenErrs[:2]},
+		{recToken: "unknown", wantErrs: []error{wfp.ErrRecTokenNotFound}},
+		{recToken: "funds", wantErrs: []error{wfp.ErrInsufficientFunds}, notErrs: tokenErrs},
+		{recToken: "", wantErrs: []error{wfp.ErrRecTokenRequired}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.recToken, func(t *testing.T) {
+			resp, err := wfpClient.ChargeByToken(context.Background(), wfpClient.NewRecTokenChargeRequest(tt.recToken).
+				SetMerchantDomainName("test.com").
+				SetOrderReference("ORD-1").
+				SetOrderDate(time.Unix(1700000000, 0)).
+				SetAmount(wfp.MustParseAmount("10")).
+				SetCurrency("UAH").
+				SetClientIPAddress("10.0.0.1").
+				AddProduct("subscription", wfp.MustParseAmount("10"), 1))
+			for _, notErr := range tt.notErrs {
+				require.NotErrorIs(t, err, notErr)
+			}
+			for _, wantErr := range tt.wantErrs {
+				require.ErrorIs(t, err, wantErr)
+			}
+			if tt.wantErrs != nil {
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "Approved", resp.TransactionStatus)
+		})
+	}
+
+	_, err = wfpClient.ChargeByToken(context.Background(), wfpClient.NewRecTokenChargeRequest("expired").
+		SetMerchantDomainName("test.com").
+		SetOrderReference("ORD-1").
+		SetOrderDate(time.Unix(1700000000, 0)).
+		SetAmount(wfp.MustParseAmount("10")).
+		SetCurrency("UAH").
+		SetClientIPAddress("10.0.0.1").
+		AddProduct("subscription", wfp.MustParseAmount("10"), 1))
+	var apiErr *wfp.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.ErrorIs(t, err, wfp.ErrExpiredCard)
+	require.Equal(t, "CHARGE", apiErr.TransactionType)
+}
@@ -1,12 +1,10 @@
 package wayforpay
 
 import (
-	"crypto/hmac"
-	"crypto/md5"
-	"encoding/hex"
+	"context"
 	"encoding/json"
-	"io"
-	"strings"
+	"strconv"
+	"time"
 )
 
 type CheckStatus struct {
@@ -22,7 +20,7 @@ func (w *WayForPay) NewCheckStatus(orderReference string) *CheckStatus {
 		TransactionType: "CHECK_STATUS",
 		MerchantAccount: w.merchantLogin,
 		OrderReference:  orderReference,
-		APIVersion:      "1",
+		APIVersion:      strconv.Itoa(w.apiVersion),
 	}
 }
 
@@ -45,42 +43,97 @@ func (c *CheckStatus) Validate() error {
 	return nil
 }
 
-func (c *CheckStatus) body(secret string) io.Reader {
-	data := []string{
+func (c *CheckStatus) validate() error {
+	return c.Validate()
+}
+
+func (c *CheckStatus) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (c *CheckStatus) method() (API, string) {
+	return APIMain, ""
+}
+
+func (c *CheckStatus) transaction() (transactionType, orderReference string) {
+	return c.TransactionType, c.OrderReference
+}
+
+func (c *CheckStatus) idempotent() bool {
+	return true
+}
+
+func (c *CheckStatus) signatureFields() []string {
+	return []string{
 		c.MerchantAccount,
 		c.OrderReference,
 	}
+}
 
-	message := strings.Join(data, ";")
-	h := hmac.New(md5.New, []byte(secret))
-	h.Write([]byte(message))
-	c.MerchantSignature = hex.EncodeToString(h.Sum(nil))
+func (c *CheckStatus) setSignature(signature string) {
+	c.MerchantSignature = signature
+}
 
-	body, err := json.Marshal(c)
-	if err != nil {
-		return nil
+type CheckStatusResponse struct {
+	MerchantAccount   string `json:"merchantAccount"`
+	OrderReference    string `json:"orderReference"`
+	MerchantSignature string `json:"merchantSignature"`
+	Money
+	AuthCode          string    `json:"authCode"`
+	CreatedDate       time.Time `json:"createdDate"`
+	ProcessingDate    time.Time `json:"processingDate"`
+	CardPan           string    `json:"cardPan"`
+	CardType          string    `json:"cardType"`
+	IssuerBankCountry string    `json:"issuerBankCountry"`
+	IssuerBankName    string    `json:"issuerBankName"`
+	RecToken          string    `json:"recToken"`
+	PaymentSystem     string    `json:"paymentSystem"`
+	TransactionStatus string    `json:"transactionStatus"`
+	Reason            string    `json:"reason"`
+	ReasonCode        int       `json:"reasonCode"`
+	RefundAmount      Amount    `json:"refundAmount"`
+	SettlementDate    time.Time `json:"settlementDate"`
+	SettlementAmount  Amount    `json:"settlementAmount"`
+	Fee               Amount    `json:"fee"`
+}
+
+// UnmarshalJSON decodes the CHECK_STATUS answer, accepting unix timestamps,
+// dd.mm.yyyy dates and amounts sent either as numbers or as strings.
+func (c *CheckStatusResponse) UnmarshalJSON(data []byte) error {
+	type alias CheckStatusResponse
+	aux := struct {
+		*alias
+		CreatedDate    jsonTime `json:"createdDate"`
+		ProcessingDate jsonTime `json:"processingDate"`
+		ReasonCode     jsonInt  `json:"reasonCode"`
+		SettlementDate jsonTime `json:"settlementDate"`
+		Fee            jsonFee  `json:"fee"`
+	}{alias: (*alias)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
 	}
+	c.CreatedDate = time.Time(aux.CreatedDate)
+	c.ProcessingDate = time.Time(aux.ProcessingDate)
+	c.ReasonCode = int(aux.ReasonCode)
+	c.SettlementDate = time.Time(aux.SettlementDate)
+	c.Fee = Amount(aux.Fee)
+	return nil
+}
 
-	return strings.NewReader(string(body))
+func (c *CheckStatusResponse) Error() error {
+	return reasonError(c.ReasonCode, c.Reason)
 }
 
-type CheckStatusResponse struct {
-	MerchantAccount   string  `json:"merchantAccount"`
-	OrderReference    string  `json:"orderReference"`
-	MerchantSignature string  `json:"merchantSignature"`
-	Amount            string  `json:"amount"`
-	Currency          string  `json:"currency"`
-	AuthCode          string  `json:"authCode"`
-	CreatedDate       int     `json:"createdDate"`
-	ProcessingDate    int     `json:"processingDate"`
-	CardPan           string  `json:"cardPan"`
-	CardType          string  `json:"cardType"`
-	IssuerBankCountry string  `json:"issuerBankCountry"`
-	IssuerBankName    string  `json:"issuerBankName"`
-	TransactionStatus string  `json:"transactionStatus"`
-	Reason            string  `json:"reason"`
-	ReasonCode        string  `json:"reasonCode"`
-	SettlementDate    string  `json:"settlementDate"`
-	SettlementAmount  float64 `json:"settlementAmount"`
-	Fee               float64 `json:"fee"`
+func (c *CheckStatusResponse) GetReasonCode() int {
+	return c.ReasonCode
+}
+
+func (c *CheckStatusResponse) GetReason() string {
+	return c.Reason
+}
+
+// CheckStatus requests the current state of the order identified by orderReference.
+func (w *WayForPay) CheckStatus(ctx context.Context, orderReference string) (*CheckStatusResponse, error) {
+	request := w.NewCheckStatus(orderReference)
+	return Do[*CheckStatus, CheckStatusResponse](ctx, w, request)
 }
@@ -0,0 +1,75 @@
+package wayforpay_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_CheckStatus(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        string
+		want        *wfp.CheckStatusResponse
+		expectedErr *wfp.APIError
+	}{
+		{
+			name: "approved",
+			body: `{"merchantAccount":"test_merch_n1","orderReference":"AAA","amount":600.5,"currency":"UAH",` +
+				`"createdDate":1700000000,"processingDate":"1700000060","transactionStatus":"Approved",` +
+				`"reason":"Ok","reasonCode":1100,"settlementDate":"15.11.2023","settlementAmount":"590.5","fee":10}`,
+			want: &wfp.CheckStatusResponse{
+				MerchantAccount:   merchantLogin,
+				OrderReference:    "AAA",
+				Money:             wfp.NewMoney(wfp.MustParseAmount("600.5"), "UAH"),
+				CreatedDate:       time.Unix(1700000000, 0),
+				ProcessingDate:    time.Unix(1700000060, 0),
+				TransactionStatus: "Approved",
+				Reason:            "Ok",
+				ReasonCode:        1100,
+				SettlementDate:    time.Date(2023, time.November, 15, 0, 0, 0, 0, time.UTC),
+				SettlementAmount:  wfp.MustParseAmount("590.5"),
+				Fee:               wfp.MustParseAmount("10"),
+			},
+		},
+		{
+			name: "amounts in other decimal forms",
+			body: `{"orderReference":"AAA","amount":"100.000","currency":"UAH","transactionStatus":"Refunded",` +
+				`"reason":"Ok","reasonCode":1100,"refundAmount":1.0E2,"fee":"1.125"}`,
+			want: &wfp.CheckStatusResponse{
+				OrderReference:    "AAA",
+				Money:             wfp.NewMoney(wfp.MustParseAmount("100"), "UAH"),
+				TransactionStatus: "Refunded",
+				Reason:            "Ok",
+				ReasonCode:        1100,
+				RefundAmount:      wfp.MustParseAmount("100"),
+				Fee:               wfp.MustParseAmount("1.13"),
+			},
+		},
+		{
+			name:        "declined",
+			body:        `{"orderReference":"AAA","transactionStatus":"Declined","reason":"Declined To Card Issuer","reasonCode":"1101"}`,
+			expectedErr: &wfp.APIError{ReasonCode: 1101, Reason: "Declined To Card Issuer", TransactionType: "CHECK_STATUS", OrderReference: "AAA"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			wfpClient, err := wfp.NewClient(stubClient(tt.body), merchantLogin, merchantSecret)
+			require.NoError(t, err)
+
+			got, err := wfpClient.CheckStatus(context.Background(), "AAA")
+			if tt.expectedErr != nil {
+				var apiErr *wfp.APIError
+				require.True(t, errors.As(err, &apiErr))
+				require.Equal(t, tt.expectedErr, apiErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,5 +1,17 @@
 package wayforpay
 
 const (
+	// Deprecated: use DefaultBaseURL or WithBaseURL.
 	APIEndpoint = "https://api.wayforpay.com/api%s"
+
+	DefaultBaseURL     = "https://api.wayforpay.com/api"
+	DefaultPurchaseURL = "https://secure.wayforpay.com/pay"
+	DefaultVerifyURL   = "https://secure.wayforpay.com/verify"
+	DefaultRegularURL  = "https://api.wayforpay.com/regularApi"
+	DefaultUserAgent   = "fairytale5571-wayforpay-go"
+	DefaultAPIVersion  = 1
+	DefaultLanguage    = "EN"
+
+	// DefaultMaxResponseSize is the largest answer body read, in bytes.
+	DefaultMaxResponseSize = 1 << 20
 )
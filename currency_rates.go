@@ -0,0 +1,255 @@
+package wayforpay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BaseCurrency is the currency the WayForPay exchange rates are quoted in.
+const BaseCurrency = "UAH"
+
+type CurrencyRatesRequest struct {
+	TransactionType   string `json:"transactionType"`
+	MerchantAccount   string `json:"merchantAccount"`
+	MerchantSignature string `json:"merchantSignature"`
+	ApiVersion        int    `json:"apiVersion"`
+	OrderDate         int64  `json:"orderDate"`
+}
+
+func (c *CurrencyRatesRequest) validate() error {
+	if c.TransactionType == "" {
+		return ErrTransactionTypeRequired
+	}
+	if c.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if c.OrderDate == 0 {
+		return ErrOrderDateRequired
+	}
+	return nil
+}
+
+func (c *CurrencyRatesRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (c *CurrencyRatesRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (c *CurrencyRatesRequest) transaction() (transactionType, orderReference string) {
+	return c.TransactionType, ""
+}
+
+func (c *CurrencyRatesRequest) idempotent() bool {
+	return true
+}
+
+func (c *CurrencyRatesRequest) signatureFields() []string {
+	return []string{
+		c.MerchantAccount,
+		strconv.FormatInt(c.OrderDate, 10),
+	}
+}
+
+func (c *CurrencyRatesRequest) setSignature(signature string) {
+	c.MerchantSignature = signature
+}
+
+// rateDigits is the number of fractional digits a Rate keeps; rateScale is
+// 10^rateDigits.
+const (
+	rateDigits = 8
+	rateScale  = 100_000_000
+)
+
+// Rate is an exact exchange rate with up to eight fractional digits. The
+// zero value is an unknown rate.
+type Rate struct {
+	scaled int64
+}
+
+// ParseRate parses a non-negative decimal rate such as "41.2534", in plain or
+// exponent form. Digits beyond the eighth fractional one are rounded half away
+// from zero.
+func ParseRate(s string) (Rate, error) {
+	r, ok := parseDecimal(s)
+	if !ok || r.Sign() < 0 {
+		return Rate{}, fmt.Errorf("%w: %q", ErrMalformedRate, s)
+	}
+	scaled, ok := scaleRat(r, rateScale)
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %q", ErrMalformedRate, s)
+	}
+	return Rate{scaled: scaled}, nil
+}
+
+// MustParseRate is like ParseRate but panics on a malformed rate.
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r Rate) IsZero() bool {
+	return r.scaled == 0
+}
+
+// Float64 returns the nearest float64 value. Do not use it for arithmetic.
+func (r Rate) Float64() float64 {
+	return float64(r.scaled) / rateScale
+}
+
+// String returns the rate without trailing zeros, e.g. "41.25" or "40".
+func (r Rate) String() string {
+	s := strconv.FormatInt(r.scaled, 10)
+	if len(s) <= rateDigits {
+		s = strings.Repeat("0", rateDigits-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-rateDigits], strings.TrimRight(s[len(s)-rateDigits:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// MarshalJSON encodes the rate as a JSON number.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts the rate as a JSON number or a string in the grammar
+// of ParseRate. An empty string and null decode to zero.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	raw := string(bytes.Trim(data, `"`))
+	if raw == "" || raw == "null" {
+		*r = Rate{}
+		return nil
+	}
+	v, err := ParseRate(raw)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// CurrencyRatesResponse is the exchange rate table. Rates holds the price of one
+// unit of every currency in BaseCurrency.
+type CurrencyRatesResponse struct {
+	Reason     string          `json:"reason"`
+	ReasonCode int             `json:"reasonCode"`
+	Rates      map[string]Rate `json:"rates"`
+}
+
+// Rate returns the price of one unit of currency in BaseCurrency.
+func (c *CurrencyRatesResponse) Rate(currency string) (Rate, bool) {
+	if currency == BaseCurrency {
+		return Rate{scaled: rateScale}, true
+	}
+	rate, ok := c.Rates[currency]
+	return rate, ok && rate.scaled > 0
+}
+
+// Convert converts money to another currency. The result is computed exactly
+// and rounded to minor units half away from zero.
+func (c *CurrencyRatesResponse) Convert(money Money, to string) (Money, error) {
+	fromRate, ok := c.Rate(money.Currency)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+	toRate, ok := c.Rate(to)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+	value := new(big.Int).Mul(big.NewInt(money.Amount.Minor()), big.NewInt(fromRate.scaled))
+	minor, ok := quoRound(value, big.NewInt(toRate.scaled))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s %s in %s", ErrMalformedAmount, money.Amount, money.Currency, to)
+	}
+	return NewMoney(NewAmount(minor), to), nil
+}
+
+// clone returns a copy that does not share the Rates map.
+func (c *CurrencyRatesResponse) clone() *CurrencyRatesResponse {
+	clone := *c
+	clone.Rates = maps.Clone(c.Rates)
+	return &clone
+}
+
+func (c *CurrencyRatesResponse) Error() error {
+	return reasonError(c.ReasonCode, c.Reason)
+}
+
+func (c *CurrencyRatesResponse) GetReasonCode() int {
+	return c.ReasonCode
+}
+
+func (c *CurrencyRatesResponse) GetReason() string {
+	return c.Reason
+}
+
+// ratesCache keeps the last exchange rate table for the TTL set with WithCurrencyRatesTTL.
+type ratesCache struct {
+	mu        sync.Mutex
+	rates     *CurrencyRatesResponse
+	fetchedAt time.Time
+}
+
+// CurrencyRates returns the current exchange rates. When a TTL is set with
+// WithCurrencyRatesTTL, the table is requested at most once per TTL, and every
+// caller gets its own copy of the cached table.
+func (w *WayForPay) CurrencyRates(ctx context.Context) (*CurrencyRatesResponse, error) {
+	if w.ratesTTL <= 0 {
+		return w.fetchCurrencyRates(ctx)
+	}
+	w.rates.mu.Lock()
+	defer w.rates.mu.Unlock()
+	if w.rates.rates != nil && w.now().Sub(w.rates.fetchedAt) < w.ratesTTL {
+		return w.rates.rates.clone(), nil
+	}
+	rates, err := w.fetchCurrencyRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.rates.rates, w.rates.fetchedAt = rates, w.now()
+	return rates.clone(), nil
+}
+
+func (w *WayForPay) fetchCurrencyRates(ctx context.Context) (*CurrencyRatesResponse, error) {
+	request := &CurrencyRatesRequest{
+		TransactionType: "CURRENCY_RATES",
+		MerchantAccount: w.merchantLogin,
+		ApiVersion:      w.apiVersion,
+		OrderDate:       w.now().Unix(),
+	}
+	return Do[*CurrencyRatesRequest, CurrencyRatesResponse](ctx, w, request)
+}
+
+// FillAlternativeAmount converts the invoice amount to alternativeCurrency with
+// the (cached) exchange rates and sets AlternativeAmount and AlternativeCurrency.
+func (w *WayForPay) FillAlternativeAmount(ctx context.Context, request *CreateInvoiceRequest, alternativeCurrency string) error {
+	if err := request.Money.check(); err != nil {
+		return err
+	}
+	rates, err := w.CurrencyRates(ctx)
+	if err != nil {
+		return err
+	}
+	alternative, err := rates.Convert(request.Money, alternativeCurrency)
+	if err != nil {
+		return err
+	}
+	request.SetAlternativeAmount(alternative.Amount)
+	request.SetAlternativeCurrency(alternative.Currency)
+	return nil
+}
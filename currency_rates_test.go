@@ -0,0 +1,85 @@
+package wayforpay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_CurrencyRates(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	now := time.Unix(1700000000, 0)
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret,
+		wfp.WithClock(func() time.Time { return now }),
+		wfp.WithCurrencyRatesTTL(time.Hour))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	srv.SetRates(map[string]float64{"USD": 40, "EUR": 44, "PLN": 1.005})
+	rates, err := wfpClient.CurrencyRates(ctx)
+	require.NoError(t, err)
+	require.Equal(t, wfp.MustParseRate("40"), rates.Rates["USD"])
+	require.Equal(t, "1.005", rates.Rates["PLN"].String())
+
+	delete(rates.Rates, "EUR")
+	rates.Rates["USD"] = wfp.MustParseRate("1")
+	rates, err = wfpClient.CurrencyRates(ctx)
+	require.NoError(t, err)
+	require.Equal(t, wfp.MustParseRate("40"), rates.Rates["USD"])
+	require.Contains(t, rates.Rates, "EUR")
+
+	usd, err := rates.Convert(wfp.NewMoney(wfp.MustParseAmount("100"), "EUR"), "USD")
+	require.NoError(t, err)
+	require.Equal(t, wfp.NewMoney(wfp.MustParseAmount("110"), "USD"), usd)
+	_, err = rates.Convert(wfp.NewMoney(wfp.MustParseAmount("100"), "UAH"), "GBP")
+	require.ErrorIs(t, err, wfp.ErrUnknownCurrency)
+	uah, err := rates.Convert(wfp.NewMoney(wfp.MustParseAmount("1"), "PLN"), "UAH")
+	require.NoError(t, err)
+	require.Equal(t, wfp.NewMoney(wfp.MustParseAmount("1.01"), "UAH"), uah)
+
+	srv.SetRates(map[string]float64{"USD": 41, "EUR": 44})
+	invoice := wfpClient.NewCreateInvoiceRequest().SetAmount(wfp.MustParseAmount("25")).SetCurrency("USD")
+	require.NoError(t, wfpClient.FillAlternativeAmount(ctx, invoice, "UAH"))
+	require.Equal(t, wfp.MustParseAmount("1000"), invoice.AlternativeAmount)
+	require.Equal(t, "UAH", invoice.AlternativeCurrency)
+	require.Equal(t, 1, srv.Calls("CURRENCY_RATES"))
+
+	now = now.Add(time.Hour)
+	require.NoError(t, wfpClient.FillAlternativeAmount(ctx, invoice, "UAH"))
+	require.Equal(t, wfp.MustParseAmount("1025"), invoice.AlternativeAmount)
+	require.Equal(t, 2, srv.Calls("CURRENCY_RATES"))
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "41.25", want: "41.25"},
+		{in: "41.250000", want: "41.25"},
+		{in: "4.125E1", want: "41.25"},
+		{in: "0.000000015", want: "0.00000002"},
+		{in: "0", want: "0"},
+		{in: "-1", wantErr: true},
+		{in: "0x10", wantErr: true},
+		{in: "1/3", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := wfp.ParseRate(tt.in)
+			if tt.wantErr {
+				require.ErrorIs(t, err, wfp.ErrMalformedRate)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
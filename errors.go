@@ -1,21 +1,180 @@
 package wayforpay
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 var (
-	ErrMerchantLoginRequired      = errors.New("merchant login is required")
-	ErrMerchantSecretRequired     = errors.New("merchant secret is required")
-	ErrSecretCodeRequired         = errors.New("secret code is required")
-	ErrTransactionTypeRequired    = errors.New("transactionType is required")
-	ErrMerchantAccountRequired    = errors.New("merchantAccount is required")
-	ErrMerchantDomainNameRequired = errors.New("merchantDomainName is required")
-	ErrMerchantSignatureRequired  = errors.New("merchantSignature is required")
-	ErrApiVersionRequired         = errors.New("apiVersion is required")
-	ErrOrderReferenceRequired     = errors.New("orderReference is required")
-	ErrOrderDateRequired          = errors.New("orderDate is required")
-	ErrAmountRequired             = errors.New("amount is required")
-	ErrCurrencyRequired           = errors.New("currency is required")
-	ErrProductNameRequired        = errors.New("productName is required")
-	ErrProductPriceRequired       = errors.New("productPrice is required")
-	ErrProductCountRequired       = errors.New("productCount is required")
+	ErrMerchantLoginRequired       = errors.New("merchant login is required")
+	ErrMerchantSecretRequired      = errors.New("merchant secret is required")
+	ErrMerchantPasswordRequired    = errors.New("merchant password is required")
+	ErrAuthorizationTicketRequired = errors.New("authorization ticket is required")
+	ErrSecretCodeRequired          = errors.New("secret code is required")
+	ErrTransactionTypeRequired     = errors.New("transactionType is required")
+	ErrMerchantAccountRequired     = errors.New("merchantAccount is required")
+	ErrMerchantDomainNameRequired  = errors.New("merchantDomainName is required")
+	ErrMerchantSignatureRequired   = errors.New("merchantSignature is required")
+	ErrApiVersionRequired          = errors.New("apiVersion is required")
+	ErrOrderReferenceRequired      = errors.New("orderReference is required")
+	ErrOrderDateRequired           = errors.New("orderDate is required")
+	ErrAmountRequired              = errors.New("amount is required")
+	ErrCurrencyRequired            = errors.New("currency is required")
+	ErrMalformedAmount             = errors.New("malformed amount")
+	ErrMalformedRate               = errors.New("malformed exchange rate")
+	ErrProductNameRequired         = errors.New("productName is required")
+	ErrProductPriceRequired        = errors.New("productPrice is required")
+	ErrProductCountRequired        = errors.New("productCount is required")
+	ErrProductLinesMismatch        = errors.New("productName, productPrice and productCount differ in length")
+	ErrInvalidProduct              = errors.New("product needs a positive price and a positive count")
+	ErrProductAmountMismatch       = errors.New("amount does not match the product total")
+	ErrEmptyCart                   = errors.New("cart has no items")
+	ErrInvalidCartItem             = errors.New("cart item needs a name, a positive unit price and a positive quantity")
+	ErrInvalidDiscount             = errors.New("invalid cart discount")
+	ErrCartAmountMismatch          = errors.New("amount does not match the cart total")
+	ErrCardRequired                = errors.New("card, expMonth, expYear and cardCvv are required")
+	ErrClientIPAddressRequired     = errors.New("clientIpAddress is required")
+	ErrRecTokenRequired            = errors.New("recToken is required")
+	ErrRecTokenExpired             = errors.New("recToken card has expired")
+	ErrRecTokenNotFound            = errors.New("recToken not found")
+	ErrRecTokenCardBlocked         = errors.New("recToken card is blocked")
+	ErrThreeDSDataRequired         = errors.New("d3Md and d3Pares are required")
+	ErrThreeDSNotRequired          = errors.New("transaction does not wait for 3-D Secure")
+	ErrPayoutCardRequired          = errors.New("cardBeneficiary or rec2Token is required")
+	ErrRecipientNameRequired       = errors.New("recipientFirstName and recipientLastName are required")
+	ErrRegularModeRequired         = errors.New("regularMode is required")
+	ErrUnknownCurrency             = errors.New("no exchange rate for currency")
+	ErrInvalidTimeout              = errors.New("timeout is out of the allowed range")
+	ErrInvalidDateRange            = errors.New("invalid date range")
+	ErrTransactionRequired         = errors.New("transaction is required")
+	ErrResponseRequired            = errors.New("response is required")
+	ErrAlreadyApplied              = errors.New("request was applied by an attempt whose answer was lost")
+	ErrUnexpectedStatus            = errors.New("unexpected http status")
+	ErrResponseTooLarge            = errors.New("response body exceeds the size limit")
+	ErrNonJSONResponse             = errors.New("response body is not JSON")
+	ErrTruncatedResponse           = errors.New("response body was cut short")
+
+	ErrInvalidNotificationSignature = errors.New("invalid notification signature")
+	ErrMerchantAccountMismatch      = errors.New("merchantAccount does not match the client")
+	ErrNotificationTooLarge         = errors.New("notification body exceeds the size limit")
 )
+
+// APIError is returned when WayForPay answers with a reason code other than ReasonCodeOk.
+// It matches the sentinel error of its reason code with errors.Is:
+//
+//	if errors.Is(err, wayforpay.ErrInsufficientFunds) { ... }
+type APIError struct {
+	ReasonCode      int
+	Reason          string
+	TransactionType string
+	OrderReference  string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api error: code: %v, reason: %v", e.ReasonCode, e.Reason)
+	if e.TransactionType != "" {
+		msg += fmt.Sprintf(", transaction: %v", e.TransactionType)
+	}
+	if e.OrderReference != "" {
+		msg += fmt.Sprintf(", order: %v", e.OrderReference)
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error of the reason code, if it is documented.
+func (e *APIError) Unwrap() error {
+	return ReasonCodeError(e.ReasonCode)
+}
+
+// Class tells whether the operation may be retried, fixed by the customer or is final.
+func (e *APIError) Class() ReasonClass {
+	return ClassifyReasonCode(e.ReasonCode)
+}
+
+// Retryable reports whether repeating the same operation may succeed.
+func (e *APIError) Retryable() bool {
+	return e.Class() == ReasonClassRetryable
+}
+
+// CustomerFixable reports whether the customer can fix the problem, e.g. by using another card.
+func (e *APIError) CustomerFixable() bool {
+	return e.Class() == ReasonClassCustomerFixable
+}
+
+// TransportError is returned when WayForPay cannot be reached or does not
+// answer with a JSON document: a non-2xx status, an HTML maintenance page, a
+// body over the size limit or one cut short. Err is the cause, e.g.
+// ErrUnexpectedStatus, ErrNonJSONResponse or a *url.Error.
+type TransportError struct {
+	// StatusCode is 0 when no answer was received.
+	StatusCode  int
+	ContentType string
+	// Body holds up to MaxErrorBodySnippet bytes of the raw answer.
+	Body            string
+	TransactionType string
+	OrderReference  string
+	Err             error
+}
+
+// MaxErrorBodySnippet is the number of body bytes kept by a TransportError.
+const MaxErrorBodySnippet = 512
+
+func (e *TransportError) Error() string {
+	msg := "transport error"
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(": status: %v", e.StatusCode)
+	}
+	if e.ContentType != "" {
+		msg += fmt.Sprintf(", content type: %v", e.ContentType)
+	}
+	if e.TransactionType != "" {
+		msg += fmt.Sprintf(", transaction: %v", e.TransactionType)
+	}
+	if e.OrderReference != "" {
+		msg += fmt.Sprintf(", order: %v", e.OrderReference)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+	if e.Body != "" {
+		msg += fmt.Sprintf(", body: %q", e.Body)
+	}
+	return msg
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether repeating the request may succeed: the answer
+// was lost or cut short, or WayForPay answered with 5xx or 429.
+func (e *TransportError) Retryable() bool {
+	return e.StatusCode == 0 || e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests ||
+		errors.Is(e.Err, ErrTruncatedResponse)
+}
+
+// snippet returns the first MaxErrorBodySnippet bytes of body as valid UTF-8.
+func snippet(body []byte) string {
+	if len(body) > MaxErrorBodySnippet {
+		body = body[:MaxErrorBodySnippet]
+	}
+	return strings.ToValidUTF8(string(body), "")
+}
+
+// withRequest annotates an *APIError or a *TransportError with the request it
+// was returned for.
+func withRequest(err error, transactionType, orderReference string) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.TransactionType = transactionType
+		apiErr.OrderReference = orderReference
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		transportErr.TransactionType = transactionType
+		transportErr.OrderReference = orderReference
+	}
+	return err
+}
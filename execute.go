@@ -0,0 +1,115 @@
+package wayforpay
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// CustomTransaction is a request type defined outside the SDK, e.g. a WayForPay
+// transaction the SDK does not support yet. Execute signs, sends and decodes it
+// like the built-in requests.
+type CustomTransaction interface {
+	// Endpoint returns the path of the endpoint relative to the base url of
+	// its API ("" for the API endpoint itself) or an absolute url.
+	Endpoint() string
+	// SignatureFields returns the values the merchantSignature is computed from,
+	// in order. It returns nil for requests without a signature.
+	SignatureFields() []string
+	// Payload returns the value encoded as the JSON body, given the computed
+	// merchantSignature.
+	Payload(signature string) any
+}
+
+// TransactionValidator is implemented by transactions that check their fields
+// before they are sent.
+type TransactionValidator interface {
+	Validate() error
+}
+
+// TransactionDescriber is implemented by transactions that report their type
+// and order reference for errors.
+type TransactionDescriber interface {
+	TransactionType() string
+	OrderReference() string
+}
+
+// TransactionAPI is implemented by transactions posted to an API other than
+// APIMain, e.g. APIRegular. Endpoint is then relative to the base url of that API.
+type TransactionAPI interface {
+	API() API
+}
+
+// IdempotentTransaction is implemented by transactions that may be sent again
+// when a call fails with a retryable error. Other custom transactions are sent
+// once whatever the RetryPolicy.
+type IdempotentTransaction interface {
+	Idempotent() bool
+}
+
+// customRequest adapts a CustomTransaction to Payment.
+type customRequest struct {
+	tx        CustomTransaction
+	signature string
+}
+
+func (c *customRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (c *customRequest) method() (API, string) {
+	if tx, ok := c.tx.(TransactionAPI); ok {
+		return tx.API(), c.tx.Endpoint()
+	}
+	return APIMain, c.tx.Endpoint()
+}
+
+func (c *customRequest) transaction() (transactionType, orderReference string) {
+	if d, ok := c.tx.(TransactionDescriber); ok {
+		return d.TransactionType(), d.OrderReference()
+	}
+	return "", ""
+}
+
+func (c *customRequest) idempotent() bool {
+	tx, ok := c.tx.(IdempotentTransaction)
+	return ok && tx.Idempotent()
+}
+
+func (c *customRequest) signatureFields() []string {
+	return c.tx.SignatureFields()
+}
+
+func (c *customRequest) setSignature(signature string) {
+	c.signature = signature
+}
+
+func (c *customRequest) validate() error {
+	if v, ok := c.tx.(TransactionValidator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+func (c *customRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.tx.Payload(c.signature))
+}
+
+// Execute sends tx, which must not be nil, and decodes the answer into
+// response, which must be a non-nil pointer. Embed APIResponse in the response
+// type to get the reason code handling of the built-in responses.
+func (w *WayForPay) Execute(ctx context.Context, tx CustomTransaction, response Responder) error {
+	if isNil(tx) {
+		return ErrTransactionRequired
+	}
+	if isNil(response) {
+		return ErrResponseRequired
+	}
+	return w.do(ctx, &customRequest{tx: tx}, response)
+}
+
+// isNil reports whether v is nil or a nil pointer.
+func isNil(v any) bool {
+	rv := reflect.ValueOf(v)
+	return !rv.IsValid() || rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -0,0 +1,89 @@
+package wayforpay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+// clientInfo is a transaction the SDK does not implement.
+type clientInfo struct {
+	account string
+	phone   string
+}
+
+func (c clientInfo) Endpoint() string          { return "" }
+func (c clientInfo) SignatureFields() []string { return []string{c.account, c.phone} }
+func (c clientInfo) TransactionType() string   { return "GET_CLIENT" }
+func (c clientInfo) OrderReference() string    { return "" }
+func (c clientInfo) Payload(signature string) any {
+	return map[string]any{
+		"transactionType":   c.TransactionType(),
+		"merchantAccount":   c.account,
+		"phone":             c.phone,
+		"merchantSignature": signature,
+	}
+}
+
+func (c clientInfo) Validate() error {
+	if c.phone == "" {
+		return errors.New("phone is required")
+	}
+	return nil
+}
+
+type clientInfoResponse struct {
+	wfp.APIResponse
+	Name string `json:"name"`
+}
+
+func TestWayForPay_Execute(t *testing.T) {
+	var sent map[string]any
+	acquirer := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, wfp.DefaultBaseURL, req.URL.String())
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&sent))
+			answer := `{"reasonCode":1100,"reason":"Ok","name":"Taras"}`
+			if sent["phone"] == "380000000000" {
+				answer = `{"reasonCode":1127,"reason":"Client not found"}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(answer)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(acquirer, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var resp clientInfoResponse
+	require.NoError(t, wfpClient.Execute(ctx, clientInfo{account: merchantLogin, phone: "380501234567"}, &resp))
+	require.Equal(t, "Taras", resp.Name)
+	require.Equal(t, "GET_CLIENT", sent["transactionType"])
+	require.Equal(t, sign(merchantSecret, merchantLogin, "380501234567"), sent["merchantSignature"])
+
+	err = wfpClient.Execute(ctx, clientInfo{account: merchantLogin, phone: "380000000000"}, &resp)
+	var apiErr *wfp.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.ErrorIs(t, err, wfp.ErrOrderNotFound)
+	require.Equal(t, "GET_CLIENT", apiErr.TransactionType)
+
+	sent = nil
+	require.Error(t, wfpClient.Execute(ctx, clientInfo{account: merchantLogin}, &resp))
+	require.Nil(t, sent)
+
+	require.ErrorIs(t, wfpClient.Execute(ctx, clientInfo{account: merchantLogin, phone: "380501234567"}, nil), wfp.ErrResponseRequired)
+	require.ErrorIs(t, wfpClient.Execute(ctx, clientInfo{account: merchantLogin, phone: "380501234567"}, (*clientInfoResponse)(nil)), wfp.ErrResponseRequired)
+	require.ErrorIs(t, wfpClient.Execute(ctx, nil, &resp), wfp.ErrTransactionRequired)
+	require.ErrorIs(t, wfpClient.Execute(ctx, (*clientInfo)(nil), &resp), wfp.ErrTransactionRequired)
+	require.Nil(t, sent)
+}
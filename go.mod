@@ -1,11 +1,14 @@
 module github.com/fairytale5571/wayforpay
 
-go 1.20
+go 1.23
+
+require (
+	github.com/google/uuid v1.3.1
+	github.com/stretchr/testify v1.8.4
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/google/uuid v1.3.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.8.4 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,5 +1,81 @@
 package wayforpay
 
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"time"
+)
+
 func IsSuccessHttpCode(code int) bool {
 	return code >= 200 && code < 300
 }
+
+// jsonInt decodes an integer that WayForPay may send either as a JSON number or as a string.
+type jsonInt int
+
+func (i *jsonInt) UnmarshalJSON(data []byte) error {
+	raw := string(bytes.Trim(data, `"`))
+	if raw == "" || raw == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return err
+	}
+	*i = jsonInt(v)
+	return nil
+}
+
+// jsonTime decodes a unix timestamp or a dd.mm.yyyy date.
+type jsonTime time.Time
+
+func (t *jsonTime) UnmarshalJSON(data []byte) error {
+	raw := string(bytes.Trim(data, `"`))
+	if raw == "" || raw == "null" || raw == "0" {
+		*t = jsonTime(time.Time{})
+		return nil
+	}
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		*t = jsonTime(time.Unix(unix, 0))
+		return nil
+	}
+	v, err := time.Parse("02.01.2006", raw)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", raw, err)
+	}
+	*t = jsonTime(v)
+	return nil
+}
+
+// decimalPattern is the grammar of a JSON number: an optional minus sign,
+// digits, an optional fraction and an optional exponent of up to four digits.
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]{1,4})?$`)
+
+// parseDecimal returns the exact value of a decimal that WayForPay may send
+// either as a JSON number or as a string, in plain or exponent form.
+func parseDecimal(raw string) (*big.Rat, bool) {
+	if !decimalPattern.MatchString(raw) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(raw)
+}
+
+// scaleRat returns r multiplied by scale and rounded half away from zero.
+// ok is false when the result does not fit in an int64.
+func scaleRat(r *big.Rat, scale int64) (v int64, ok bool) {
+	return quoRound(new(big.Int).Mul(r.Num(), big.NewInt(scale)), r.Denom())
+}
+
+// quoRound returns x/y rounded half away from zero. ok is false when the
+// result does not fit in an int64.
+func quoRound(x, y *big.Int) (v int64, ok bool) {
+	q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+	if m.Lsh(m, 1).CmpAbs(y) >= 0 {
+		q.Add(q, big.NewInt(int64(x.Sign()*y.Sign())))
+	}
+	return q.Int64(), q.IsInt64()
+}
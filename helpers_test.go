@@ -0,0 +1,112 @@
+package wayforpay_test
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+)
+
+const (
+	merchantLogin  = "test_merch_n1"
+	merchantSecret = "flk3409refn54t54t*FNJRET"
+)
+
+// newTestClient returns a client wired to an offline WayForPay emulator.
+func newTestClient(t *testing.T) (*wfp.WayForPay, *wayforpaytest.Server) {
+	t.Helper()
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return wfpClient, srv
+}
+
+// sign returns the merchantSignature of fields.
+func sign(secret string, fields ...string) string {
+	h := hmac.New(md5.New, []byte(secret))
+	h.Write([]byte(strings.Join(fields, ";")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubRequest is a request received by a stub, its JSON body decoded.
+type stubRequest struct {
+	URL    string
+	Header http.Header
+	Body   map[string]any
+}
+
+// stub is a transport that records every request and answers it with the
+// body returned by answer.
+type stub struct {
+	answer        func(req stubRequest) string
+	status        int
+	contentType   string
+	contentLength int64
+	requests      []stubRequest
+}
+
+// newStub returns a stub answering 200 OK with application/json bodies.
+func newStub(answer func(req stubRequest) string) *stub {
+	return &stub{answer: answer, status: http.StatusOK, contentType: "application/json"}
+}
+
+// withStatus makes the stub answer with status and contentType.
+func (s *stub) withStatus(status int, contentType string) *stub {
+	s.status, s.contentType = status, contentType
+	return s
+}
+
+// withContentLength makes the stub announce contentLength bytes.
+func (s *stub) withContentLength(contentLength int64) *stub {
+	s.contentLength = contentLength
+	return s
+}
+
+func (s *stub) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+func (s *stub) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := stubRequest{URL: req.URL.String(), Header: req.Header.Clone()}
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&recorded.Body)
+	}
+	s.requests = append(s.requests, recorded)
+	return &http.Response{
+		StatusCode:    s.status,
+		Header:        http.Header{"Content-Type": {s.contentType}},
+		Body:          io.NopCloser(strings.NewReader(s.answer(recorded))),
+		ContentLength: s.contentLength,
+		Request:       req,
+	}, nil
+}
+
+// urls returns the urls of the recorded requests.
+func (s *stub) urls() []string {
+	urls := make([]string, 0, len(s.requests))
+	for _, req := range s.requests {
+		urls = append(urls, req.URL)
+	}
+	return urls
+}
+
+// stubClient answers every request with body.
+func stubClient(body string) *http.Client {
+	return newStub(func(stubRequest) string { return body }).Client()
+}
@@ -0,0 +1,58 @@
+package wayforpay
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call describes a WayForPay call to an Interceptor.
+type Call struct {
+	TransactionType string
+	OrderReference  string
+	// Money is the amount and currency of the request, zero for requests
+	// without one, e.g. CHECK_STATUS.
+	Money Money
+	// Request is the typed request, e.g. *CreateInvoiceRequest or the
+	// CustomTransaction passed to Execute. It is signed after the whole chain
+	// has run, so an interceptor may still change it.
+	Request any
+	// Header holds extra HTTP headers sent with the request, e.g. a
+	// correlation id.
+	Header http.Header
+
+	payment    Payment
+	attempts   int
+	httpStatus int
+}
+
+// Invoker sends the call and decodes the answer into response.
+type Invoker func(ctx context.Context, call *Call, response Responder) error
+
+// Interceptor wraps every call made by the client. It runs before the request
+// is signed and sends it by calling next; when next returns, response is
+// decoded and the error is the one the caller will get, an *APIError or a
+// *TransportError included. An interceptor may return a different error.
+type Interceptor func(ctx context.Context, call *Call, response Responder, next Invoker) error
+
+func newCall(request Payment) *Call {
+	call := &Call{Request: request, Header: http.Header{}, payment: request}
+	call.TransactionType, call.OrderReference = request.transaction()
+	if c, ok := request.(*customRequest); ok {
+		call.Request = c.tx
+	}
+	if m, ok := request.(interface{ money() Money }); ok {
+		call.Money = m.money()
+	}
+	return call
+}
+
+// intercept runs the interceptors of w around invoke, the first one outermost.
+func (w *WayForPay) intercept(ctx context.Context, call *Call, response Responder, invoke Invoker) error {
+	for i := len(w.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := w.interceptors[i], invoke
+		invoke = func(ctx context.Context, call *Call, response Responder) error {
+			return interceptor(ctx, call, response, next)
+		}
+	}
+	return invoke(ctx, call, response)
+}
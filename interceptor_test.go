@@ -0,0 +1,101 @@
+package wayforpay_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_Interceptors(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	var requestIDs []string
+	transport := srv.Client().Transport
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requestIDs = append(requestIDs, req.Header.Get("X-Request-ID"))
+			return transport.RoundTrip(req)
+		}),
+	}
+
+	var trace []string
+	var calls []wfp.Call
+	var responses []wfp.Responder
+	var errs []error
+	correlation := func(ctx context.Context, call *wfp.Call, response wfp.Responder, next wfp.Invoker) error {
+		trace = append(trace, "correlation")
+		call.Header.Set("X-Request-ID", "req-"+call.OrderReference)
+		return next(ctx, call, response)
+	}
+	audit := func(ctx context.Context, call *wfp.Call, response wfp.Responder, next wfp.Invoker) error {
+		trace = append(trace, "audit")
+		err := next(ctx, call, response)
+		calls = append(calls, *call)
+		responses = append(responses, response)
+		errs = append(errs, err)
+		return err
+	}
+	wfpClient, err := wfp.NewClient(httpClient, merchantLogin, merchantSecret, wfp.WithInterceptors(correlation, audit))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	invoice := wfpClient.NewCreateInvoiceRequest().
+		SetMerchantDomainName("test.com").
+		SetOrderDate(time.Now()).
+		SetMoney(wfp.NewMoney(wfp.MustParseAmount("10.50"), "UAH")).
+		SetOrderReference("ORDER-1").
+		AddProduct("test", wfp.MustParseAmount("10.50"), 1)
+	resp, err := wfpClient.CreateInvoiceContext(ctx, invoice)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"correlation", "audit"}, trace)
+	require.Equal(t, []string{"req-ORDER-1"}, requestIDs)
+	require.Equal(t, "CREATE_INVOICE", calls[0].TransactionType)
+	require.Equal(t, "ORDER-1", calls[0].OrderReference)
+	require.Equal(t, wfp.NewMoney(wfp.MustParseAmount("10.50"), "UAH"), calls[0].Money)
+	require.Same(t, invoice, calls[0].Request)
+	require.Same(t, resp, responses[0])
+	require.NoError(t, errs[0])
+
+	srv.FailNext("CHECK_STATUS", 1131, "In processing")
+	_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+	require.ErrorIs(t, err, wfp.ErrTransactionInProcessing)
+	require.Equal(t, "CHECK_STATUS", calls[1].TransactionType)
+	require.True(t, calls[1].Money.Amount.IsZero())
+	var apiErr *wfp.APIError
+	require.ErrorAs(t, errs[1], &apiErr)
+	require.Equal(t, 1131, apiErr.ReasonCode)
+
+	t.Run("interceptor may replace the error and change the request", func(t *testing.T) {
+		errBlocked := errors.New("blocked by policy")
+		wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret, wfp.WithInterceptors(
+			func(ctx context.Context, call *wfp.Call, response wfp.Responder, next wfp.Invoker) error {
+				if call.TransactionType == "REFUND" {
+					return errBlocked
+				}
+				if request, ok := call.Request.(*wfp.CreateInvoiceRequest); ok {
+					request.SetOrderReference("PREFIX-" + request.OrderReference)
+				}
+				return next(ctx, call, response)
+			},
+		))
+		require.NoError(t, err)
+
+		_, err = wfpClient.CreateRefundContext(ctx, wfpClient.NewRefundRequest().
+			SetOrderReference("ORDER-1").
+			SetMoney(wfp.NewMoney(wfp.MustParseAmount("1"), "UAH")))
+		require.ErrorIs(t, err, errBlocked)
+		require.Zero(t, srv.Calls("REFUND"))
+
+		_, err = wfpClient.CreateInvoiceContext(ctx, invoice.SetOrderReference("ORDER-2"))
+		require.NoError(t, err)
+		_, ok := srv.Order("PREFIX-ORDER-2")
+		require.True(t, ok, "the request is signed after the chain ran")
+	})
+}
@@ -1,12 +1,8 @@
 package wayforpay
 
 import (
-	"crypto/hmac"
-	"crypto/md5"
-	"encoding/hex"
+	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -25,31 +21,42 @@ type CreateInvoiceRequest struct {
 	ServiceUrl              string        `json:"serviceUrl,omitempty"`
 	OrderReference          string        `json:"orderReference"`
 	OrderDate               int64         `json:"orderDate"`
-	Amount                  string        `json:"amount"`
-	Currency                string        `json:"currency"`
-	AlternativeAmount       string        `json:"alternativeAmount,omitempty"`
-	AlternativeCurrency     string        `json:"alternativeCurrency,omitempty"`
-	OrderTimeout            time.Duration `json:"orderTimeout,omitempty"`
-	HoldTimeout             string        `json:"holdTimeout,omitempty"`
-	ProductName             []string      `json:"productName"`
-	ProductPrice            []string      `json:"productPrice"`
-	ProductCount            []string      `json:"productCount"`
-	PaymentSystems          string        `json:"paymentSystems,omitempty"`
-	ClientFirstName         string        `json:"clientFirstName,omitempty"`
-	ClientLastName          string        `json:"clientLastName,omitempty"`
-	ClientEmail             string        `json:"clientEmail,omitempty"`
-	ClientPhone             string        `json:"clientPhone,omitempty"`
+	Money
+	AlternativeAmount   Amount   `json:"-"`
+	AlternativeCurrency string   `json:"alternativeCurrency,omitempty"`
+	OrderTimeout        Timeout  `json:"orderTimeout,omitempty"`
+	HoldTimeout         Timeout  `json:"holdTimeout,omitempty"`
+	OrderLifetime       Timeout  `json:"orderLifetime,omitempty"`
+	ProductName         []string `json:"productName"`
+	ProductPrice        []Amount `json:"productPrice"`
+	ProductCount        []int    `json:"productCount"`
+	Cart                *Cart    `json:"-"`
+	PaymentSystems      string   `json:"paymentSystems,omitempty"`
+	ClientFirstName     string   `json:"clientFirstName,omitempty"`
+	ClientLastName      string   `json:"clientLastName,omitempty"`
+	ClientEmail         string   `json:"clientEmail,omitempty"`
+	ClientPhone         string   `json:"clientPhone,omitempty"`
+}
+
+// MarshalJSON encodes the request, leaving alternativeAmount out when it is zero.
+func (c CreateInvoiceRequest) MarshalJSON() ([]byte, error) {
+	type alias CreateInvoiceRequest
+	return json.Marshal(struct {
+		alias
+		AlternativeAmount *Amount `json:"alternativeAmount,omitempty"`
+	}{alias: alias(c), AlternativeAmount: c.AlternativeAmount.orNil()})
 }
 
 // NewCreateInvoiceRequest returns a new CreateInvoiceRequest.
 func (w *WayForPay) NewCreateInvoiceRequest() *CreateInvoiceRequest {
 	return &CreateInvoiceRequest{
 		TransactionType:  "CREATE_INVOICE",
-		ApiVersion:       "1",
-		Language:         "EN",
+		ApiVersion:       strconv.Itoa(w.apiVersion),
+		Language:         w.language,
 		NotifyMethod:     "all",
 		MerchantAccount:  w.merchantLogin,
 		MerchantAuthType: SignatureModeSimple,
+		OrderTimeout:     DefaultOrderTimeout,
 	}
 }
 
@@ -57,35 +64,43 @@ func (c *CreateInvoiceRequest) params() (Params, error) {
 	return Params{}, nil
 }
 
-func (c *CreateInvoiceRequest) method() string {
-	return "/pay"
+func (c *CreateInvoiceRequest) method() (API, string) {
+	return APIMain, ""
 }
 
-func (c *CreateInvoiceRequest) body(secret string) io.Reader {
+func (c *CreateInvoiceRequest) transaction() (transactionType, orderReference string) {
+	return c.TransactionType, c.OrderReference
+}
+
+// reconcile treats an order that appeared since the first attempt as the
+// invoice created by it. The invoice url is not known then, so the call ends
+// with ErrAlreadyApplied.
+func (c *CreateInvoiceRequest) reconcile(before, after *CheckStatusResponse, _ Responder) (bool, error) {
+	if before != nil || after == nil {
+		return false, nil
+	}
+	return true, ErrAlreadyApplied
+}
+
+func (c *CreateInvoiceRequest) signatureFields() []string {
+	if c.Cart != nil {
+		c.ProductName, c.ProductPrice, c.ProductCount = c.Cart.products()
+	}
 	data := []string{
 		c.MerchantAccount,
 		c.MerchantDomainName,
 		c.OrderReference,
 		strconv.FormatInt(c.OrderDate, 10),
-		c.Amount,
+		c.Amount.String(),
 		c.Currency,
 	}
 
-	data = append(data, c.ProductName...)
-	data = append(data, c.ProductCount...)
-	data = append(data, c.ProductPrice...)
-
-	message := strings.Join(data, ";")
-	h := hmac.New(md5.New, []byte(secret))
-	h.Write([]byte(message))
-	c.MerchantSignature = hex.EncodeToString(h.Sum(nil))
-
-	body, err := json.Marshal(c)
-	if err != nil {
-		return nil
-	}
+	data = append(data, productFields(c.ProductName, c.ProductPrice, c.ProductCount)...)
+	return data
+}
 
-	return strings.NewReader(string(body))
+func (c *CreateInvoiceRequest) setSignature(signature string) {
+	c.MerchantSignature = signature
 }
 
 // SetMerchantAccount sets the merchant account.
@@ -154,7 +169,7 @@ func (c *CreateInvoiceRequest) SetOrderDate(orderDate time.Time) *CreateInvoiceR
 	return c
 }
 
-func (c *CreateInvoiceRequest) SetAmount(amount string) *CreateInvoiceRequest {
+func (c *CreateInvoiceRequest) SetAmount(amount Amount) *CreateInvoiceRequest {
 	c.Amount = amount
 	return c
 }
@@ -164,7 +179,13 @@ func (c *CreateInvoiceRequest) SetCurrency(currency string) *CreateInvoiceReques
 	return c
 }
 
-func (c *CreateInvoiceRequest) SetAlternativeAmount(alternativeAmount string) *CreateInvoiceRequest {
+// SetMoney sets the amount and the currency.
+func (c *CreateInvoiceRequest) SetMoney(money Money) *CreateInvoiceRequest {
+	c.Money = money
+	return c
+}
+
+func (c *CreateInvoiceRequest) SetAlternativeAmount(alternativeAmount Amount) *CreateInvoiceRequest {
 	c.AlternativeAmount = alternativeAmount
 	return c
 }
@@ -174,20 +195,39 @@ func (c *CreateInvoiceRequest) SetAlternativeCurrency(alternativeCurrency string
 	return c
 }
 
+// SetOrderTimeout sets the time the customer has to pay once the payment is started.
+// Default: 24 hours
 func (c *CreateInvoiceRequest) SetOrderTimeout(orderTimeout time.Duration) *CreateInvoiceRequest {
-	c.OrderTimeout = orderTimeout
+	c.OrderTimeout = NewTimeout(orderTimeout)
+	return c
+}
+
+// SetHoldTimeout sets how long the funds of an AUTH payment are held until SETTLE.
+func (c *CreateInvoiceRequest) SetHoldTimeout(holdTimeout time.Duration) *CreateInvoiceRequest {
+	c.HoldTimeout = NewTimeout(holdTimeout)
 	return c
 }
 
-func (c *CreateInvoiceRequest) SetHoldTimeout(holdTimeout string) *CreateInvoiceRequest {
-	c.HoldTimeout = holdTimeout
+// SetOrderLifetime sets how long the invoice can be paid.
+func (c *CreateInvoiceRequest) SetOrderLifetime(orderLifetime time.Duration) *CreateInvoiceRequest {
+	c.OrderLifetime = NewTimeout(orderLifetime)
 	return c
 }
 
-func (c *CreateInvoiceRequest) AddProduct(productName, productPrice, productCount string) *CreateInvoiceRequest {
-	c.ProductName = append(c.ProductName, productName)
-	c.ProductPrice = append(c.ProductPrice, productPrice)
-	c.ProductCount = append(c.ProductCount, productCount)
+// AddProduct adds a product line of count units at price each. Unless a cart
+// is set, the lines must add up to the amount.
+func (c *CreateInvoiceRequest) AddProduct(name string, price Amount, count int) *CreateInvoiceRequest {
+	c.ProductName = append(c.ProductName, name)
+	c.ProductPrice = append(c.ProductPrice, price)
+	c.ProductCount = append(c.ProductCount, count)
+	return c
+}
+
+// SetCart sets the product lines and the amount from cart. The cart replaces the
+// lines added with AddProduct and must still match the amount when sent.
+func (c *CreateInvoiceRequest) SetCart(cart *Cart) *CreateInvoiceRequest {
+	c.Cart = cart
+	c.Amount = cart.Total()
 	return c
 }
 
@@ -239,20 +279,14 @@ func (c *CreateInvoiceRequest) validate() error {
 	if c.OrderDate == 0 {
 		return ErrOrderDateRequired
 	}
-	if c.Amount == "" {
-		return ErrAmountRequired
-	}
-	if c.Currency == "" {
-		return ErrCurrencyRequired
-	}
-	if len(c.ProductName) == 0 {
-		return ErrProductNameRequired
+	if err := c.Money.check(); err != nil {
+		return err
 	}
-	if len(c.ProductPrice) == 0 {
-		return ErrProductPriceRequired
+	if err := validateTimeouts(c.OrderTimeout, c.HoldTimeout, c.OrderLifetime); err != nil {
+		return err
 	}
-	if len(c.ProductCount) == 0 {
-		return ErrProductCountRequired
+	if err := checkProducts(c.Amount, c.Cart, c.ProductName, c.ProductPrice, c.ProductCount); err != nil {
+		return err
 	}
 	return nil
 }
@@ -265,10 +299,7 @@ type CreateInvoiceResponse struct {
 }
 
 func (c *CreateInvoiceResponse) Error() error {
-	if c.ReasonCode != 1100 {
-		return fmt.Errorf("%d: %s", c.ReasonCode, c.Reason)
-	}
-	return nil
+	return reasonError(c.ReasonCode, c.Reason)
 }
 
 func (c *CreateInvoiceResponse) GetReasonCode() int {
@@ -279,21 +310,14 @@ func (c *CreateInvoiceResponse) GetReason() string {
 	return c.Reason
 }
 
+// CreateInvoice sends the invoice to WayForPay.
 func (w *WayForPay) CreateInvoice(request *CreateInvoiceRequest) (*CreateInvoiceResponse, error) {
+	return w.CreateInvoiceContext(context.Background(), request)
+}
 
-	respBody := request.body(w.merchantSecret)
-	if err := request.validate(); err != nil {
-		return nil, err
-	}
-	params, err := request.params()
-	if err != nil {
-		return nil, err
-	}
-	var cir CreateInvoiceResponse
-	if err := w.makeRequest(fmt.Sprintf(APIEndpoint, request.method()), respBody, &cir, params); err != nil {
-		return nil, err
-	}
-	return &cir, nil
+// CreateInvoiceContext sends the invoice to WayForPay, aborting the call when ctx is done.
+func (w *WayForPay) CreateInvoiceContext(ctx context.Context, request *CreateInvoiceRequest) (*CreateInvoiceResponse, error) {
+	return Do[*CreateInvoiceRequest, CreateInvoiceResponse](ctx, w, request)
 }
 
 type RemoveInvoiceRequest struct {
@@ -308,7 +332,7 @@ type RemoveInvoiceRequest struct {
 func (w *WayForPay) NewRemoveInvoiceRequest() *RemoveInvoiceRequest {
 	return &RemoveInvoiceRequest{
 		TransactionType: "REMOVE_INVOICE",
-		ApiVersion:      "1",
+		ApiVersion:      strconv.Itoa(w.apiVersion),
 	}
 }
 
@@ -335,27 +359,27 @@ func (r *RemoveInvoiceRequest) params() (Params, error) {
 	return Params{}, nil
 }
 
-func (r *RemoveInvoiceRequest) method() string {
-	return "/pay"
+func (r *RemoveInvoiceRequest) method() (API, string) {
+	return APIMain, ""
 }
 
-func (r *RemoveInvoiceRequest) body(secret string) io.Reader {
-	data := []string{
-		r.MerchantAccount,
-		r.OrderReference,
-	}
+func (r *RemoveInvoiceRequest) transaction() (transactionType, orderReference string) {
+	return r.TransactionType, r.OrderReference
+}
 
-	message := strings.Join(data, ";")
-	h := hmac.New(md5.New, []byte(secret))
-	h.Write([]byte(message))
-	r.MerchantSignature = hex.EncodeToString(h.Sum(nil))
+func (r *RemoveInvoiceRequest) idempotent() bool {
+	return true
+}
 
-	body, err := json.Marshal(r)
-	if err != nil {
-		return nil
+func (r *RemoveInvoiceRequest) signatureFields() []string {
+	return []string{
+		r.MerchantAccount,
+		r.OrderReference,
 	}
+}
 
-	return strings.NewReader(string(body))
+func (r *RemoveInvoiceRequest) setSignature(signature string) {
+	r.MerchantSignature = signature
 }
 
 func (r *RemoveInvoiceRequest) validate() error {
@@ -371,21 +395,14 @@ func (r *RemoveInvoiceRequest) validate() error {
 	return nil
 }
 
+// RemoveInvoice removes a previously created invoice.
 func (w *WayForPay) RemoveInvoice(request *RemoveInvoiceRequest) (*RemoveInvoiceResponse, error) {
+	return w.RemoveInvoiceContext(context.Background(), request)
+}
 
-	respBody := request.body(w.merchantSecret)
-	if err := request.validate(); err != nil {
-		return nil, err
-	}
-	params, err := request.params()
-	if err != nil {
-		return nil, err
-	}
-	var rir RemoveInvoiceResponse
-	if err := w.makeRequest(fmt.Sprintf(APIEndpoint, request.method()), respBody, &rir, params); err != nil {
-		return nil, err
-	}
-	return &rir, nil
+// RemoveInvoiceContext removes a previously created invoice, aborting the call when ctx is done.
+func (w *WayForPay) RemoveInvoiceContext(ctx context.Context, request *RemoveInvoiceRequest) (*RemoveInvoiceResponse, error) {
+	return Do[*RemoveInvoiceRequest, RemoveInvoiceResponse](ctx, w, request)
 }
 
 type RemoveInvoiceResponse struct {
@@ -394,10 +411,7 @@ type RemoveInvoiceResponse struct {
 }
 
 func (r *RemoveInvoiceResponse) Error() error {
-	if r.ReasonCode != 1100 {
-		return fmt.Errorf("%d: %s", r.ReasonCode, r.Reason)
-	}
-	return nil
+	return reasonError(r.ReasonCode, r.Reason)
 }
 
 func (r *RemoveInvoiceResponse) GetReasonCode() int {
@@ -1,18 +1,19 @@
 package wayforpay_test
 
 import (
-	wfp "github.com/fairytale5571/wayforpay"
-	"github.com/google/uuid"
-	"github.com/stretchr/testify/require"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
-	"strconv"
+	"strings"
 	"testing"
 	"time"
-)
 
-const (
-	merchantLogin  = "test_merch_n1"
-	merchantSecret = "flk3409refn54t54t*FNJRET"
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWayForPay_NewCreateInvoiceRequest(t *testing.T) {
@@ -23,7 +24,7 @@ func TestWayForPay_NewCreateInvoiceRequest(t *testing.T) {
 		{
 			name: "success",
 			want: &wfp.CreateInvoiceRequest{
-				TransactionType: "CREATE_INVOICE", MerchantAccount: merchantLogin, MerchantTransactionType: "", MerchantAuthType: wfp.SignatureModeSimple, MerchantDomainName: "", MerchantSignature: "", ApiVersion: "1", Language: "EN", NotifyMethod: "all", ServiceUrl: "", OrderReference: "", OrderDate: 0, Amount: "", Currency: "", AlternativeAmount: "", AlternativeCurrency: "", OrderTimeout: 86400, HoldTimeout: "", ProductName: []string(nil), ProductPrice: []string(nil), ProductCount: []string(nil), PaymentSystems: "", ClientFirstName: "", ClientLastName: "", ClientEmail: "", ClientPhone: "",
+				TransactionType: "CREATE_INVOICE", MerchantAccount: merchantLogin, MerchantTransactionType: "", MerchantAuthType: wfp.SignatureModeSimple, MerchantDomainName: "", MerchantSignature: "", ApiVersion: "1", Language: "EN", NotifyMethod: "all", ServiceUrl: "", OrderReference: "", OrderDate: 0, Money: wfp.Money{}, AlternativeAmount: wfp.Amount{}, AlternativeCurrency: "", OrderTimeout: 86400, HoldTimeout: 0, ProductName: []string(nil), ProductPrice: []wfp.Amount(nil), ProductCount: []int(nil), PaymentSystems: "", ClientFirstName: "", ClientLastName: "", ClientEmail: "", ClientPhone: "",
 			},
 		},
 	}
@@ -44,14 +45,7 @@ func TestWayForPay_NewCreateInvoiceRequest(t *testing.T) {
 }
 
 func TestWayForPay_SendInvoice(t *testing.T) {
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	wfpClient, err := wfp.NewClient(client, merchantLogin, merchantSecret)
-	if err != nil {
-		t.Fatalf("NewClient() error = %v", err)
-	}
+	wfpClient, _ := newTestClient(t)
 	cases := []struct {
 		name        string
 		request     *wfp.CreateInvoiceRequest
@@ -63,20 +57,20 @@ func TestWayForPay_SendInvoice(t *testing.T) {
 			request: wfpClient.NewCreateInvoiceRequest().
 				SetMerchantDomainName("test.com").
 				SetOrderDate(time.Now()).
-				SetAmount("100").
+				SetAmount(wfp.MustParseAmount("100")).
 				SetCurrency("UAH").
 				SetOrderReference(uuid.New().String()).
-				AddProduct("test", "100", "1"),
+				AddProduct("test", wfp.MustParseAmount("100"), 1),
 		},
 		{
 			name: "success usd",
 			//request2: wfpClient.NewCreateInvoiceRequest().
 			//	SetMerchantDomainName("test.com").
 			//	SetOrderDate(time.Now()).
-			//	SetAmount("100").
+			//	SetAmount(wfp.MustParseAmount("100")).
 			//	SetCurrency("USD").
 			//	SetOrderReference(uuid.New().String()).
-			//	AddProduct("🥇test", "100", "1"),
+			//	AddProduct("🥇test", wfp.MustParseAmount("100"), 1),
 
 			request: wfpClient.NewCreateInvoiceRequest().
 				//SetServiceUrl(fmt.Sprintf("%s%s", os.Getenv("URL"), "/callback/wfp")).
@@ -85,12 +79,12 @@ func TestWayForPay_SendInvoice(t *testing.T) {
 				SetOrderDate(time.Now()).
 				SetCurrency("USD").
 				SetAlternativeCurrency("UAH").
-				SetAmount(strconv.FormatFloat(600.00, 'f', 1, 64)).
+				SetAmount(wfp.MustParseAmount("600.00")).
 				SetOrderTimeout(60*time.Minute).
 				AddProduct(
 					"🥇test",
-					strconv.FormatFloat(600.00, 'f', 1, 64),
-					"1",
+					wfp.MustParseAmount("600.00"),
+					1,
 				),
 		},
 		{
@@ -98,10 +92,10 @@ func TestWayForPay_SendInvoice(t *testing.T) {
 			request: wfpClient.NewCreateInvoiceRequest().
 				SetMerchantDomainName("test.com").
 				SetOrderDate(time.Now()).
-				SetAmount("100").
+				SetAmount(wfp.MustParseAmount("100")).
 				SetCurrency("UAH_NOT_RUB").
 				SetOrderReference(uuid.New().String()).
-				AddProduct("test", "100", "1"),
+				AddProduct("test", wfp.MustParseAmount("100"), 1),
 			expectedErr: true,
 		},
 	}
@@ -117,3 +111,111 @@ func TestWayForPay_SendInvoice(t *testing.T) {
 		})
 	}
 }
+
+func TestWayForPay_InvoiceLifecycle(t *testing.T) {
+	wfpClient, srv := newTestClient(t)
+	ctx := context.Background()
+
+	newInvoice := func(orderReference string) *wfp.CreateInvoiceRequest {
+		return wfpClient.NewCreateInvoiceRequest().
+			SetMerchantDomainName("test.com").
+			SetOrderDate(time.Now()).
+			SetAmount(wfp.MustParseAmount("100")).
+			SetCurrency("UAH").
+			SetOrderReference(orderReference).
+			AddProduct("test", wfp.MustParseAmount("100"), 1)
+	}
+
+	paid := uuid.NewString()
+	_, err := wfpClient.CreateInvoiceContext(ctx, newInvoice(paid))
+	require.NoError(t, err)
+
+	_, err = wfpClient.CreateInvoiceContext(ctx, newInvoice(paid))
+	var apiErr *wfp.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, wayforpaytest.ReasonDuplicateOrder, apiErr.ReasonCode)
+
+	require.NoError(t, srv.Approve(paid))
+	status, err := wfpClient.CheckStatus(ctx, paid)
+	require.NoError(t, err)
+	require.Equal(t, wayforpaytest.StatusApproved, status.TransactionStatus)
+
+	_, err = wfpClient.CreateRefundContext(ctx, wfpClient.NewRefundRequest().
+		SetOrderReference(paid).
+		SetAmount(wfp.MustParseAmount("100")).
+		SetCurrency("UAH"))
+	require.NoError(t, err)
+	order, _ := srv.Order(paid)
+	require.Equal(t, wayforpaytest.StatusRefunded, order.Status)
+
+	declined := uuid.NewString()
+	_, err = wfpClient.CreateInvoiceContext(ctx, newInvoice(declined))
+	require.NoError(t, err)
+	require.NoError(t, srv.Decline(declined, 1104, "Insufficient Funds"))
+	_, err = wfpClient.CheckStatus(ctx, declined)
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, 1104, apiErr.ReasonCode)
+
+	removed := uuid.NewString()
+	_, err = wfpClient.CreateInvoiceContext(ctx, newInvoice(removed))
+	require.NoError(t, err)
+	srv.FailNext("REMOVE_INVOICE", 1126, "Illegal Order State")
+	_, err = wfpClient.RemoveInvoiceContext(ctx, wfpClient.NewRemoveInvoiceRequest().
+		SetMerchantAccount(merchantLogin).
+		SetOrderReference(removed))
+	require.Error(t, err)
+	_, err = wfpClient.RemoveInvoiceContext(ctx, wfpClient.NewRemoveInvoiceRequest().
+		SetMerchantAccount(merchantLogin).
+		SetOrderReference(removed))
+	require.NoError(t, err)
+}
+
+func TestWayForPay_InvalidSignature(t *testing.T) {
+	_, srv := newTestClient(t)
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, "wrong secret")
+	require.NoError(t, err)
+
+	_, err = wfpClient.CheckStatus(context.Background(), "AAA")
+	var apiErr *wfp.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, wayforpaytest.ReasonInvalidSignature, apiErr.ReasonCode)
+}
+
+func TestWayForPay_InvoiceTimeouts(t *testing.T) {
+	var sent map[string]any
+	acquirer := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&sent))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"reasonCode":1100,"reason":"Ok"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(acquirer, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+	newInvoice := func() *wfp.CreateInvoiceRequest {
+		return wfpClient.NewCreateInvoiceRequest().
+			SetMerchantDomainName("test.com").
+			SetOrderReference(uuid.NewString()).
+			SetOrderDate(time.Now()).
+			SetAmount(wfp.MustParseAmount("100")).
+			SetCurrency("UAH").
+			AddProduct("test", wfp.MustParseAmount("100"), 1)
+	}
+
+	_, err = wfpClient.CreateInvoice(newInvoice().
+		SetOrderTimeout(90 * time.Minute).
+		SetHoldTimeout(72 * time.Hour).
+		SetOrderLifetime(7 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 5400.0, sent["orderTimeout"])
+	require.Equal(t, 259200.0, sent["holdTimeout"])
+	require.Equal(t, 604800.0, sent["orderLifetime"])
+
+	_, err = wfpClient.CreateInvoice(newInvoice().SetOrderTimeout(30 * time.Second))
+	require.ErrorIs(t, err, wfp.ErrInvalidTimeout)
+	_, err = wfpClient.CreateInvoice(newInvoice().SetHoldTimeout(31 * 24 * time.Hour))
+	require.ErrorIs(t, err, wfp.ErrInvalidTimeout)
+}
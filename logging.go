@@ -0,0 +1,194 @@
+package wayforpay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Redacted replaces a value hidden by RedactAll.
+const Redacted = "[REDACTED]"
+
+// Redaction rewrites a sensitive value before it is logged.
+type Redaction func(value string) string
+
+// RedactionPolicy maps JSON field names to the Redaction applied to their
+// values before a request or response body is logged. A field is matched at
+// any depth; every element of a list is redacted, and a value that is not a
+// string or a number is replaced with Redacted.
+type RedactionPolicy map[string]Redaction
+
+// DefaultRedactionPolicy hides signatures, passwords, tickets, recTokens, card data,
+// 3-D Secure payloads, names and IP addresses, and masks card numbers, emails
+// and phones so that they can still be told apart.
+func DefaultRedactionPolicy() RedactionPolicy {
+	return RedactionPolicy{
+		"merchantSignature":    RedactAll,
+		"signature":            RedactAll,
+		"merchantPassword":     RedactAll,
+		"authorization_ticket": RedactAll,
+		"recToken":             RedactAll,
+		"rec2Token":            RedactAll,
+		"card":                 MaskPAN,
+		"cardPan":              MaskPAN,
+		"cardBeneficiary":      MaskPAN,
+		"cardCvv":              RedactAll,
+		"cardHolder":           RedactAll,
+		"expMonth":             RedactAll,
+		"expYear":              RedactAll,
+		"d3Md":                 RedactAll,
+		"d3Pareq":              RedactAll,
+		"d3Pares":              RedactAll,
+		"clientEmail":          MaskEmail,
+		"email":                MaskEmail,
+		"clientPhone":          MaskPhone,
+		"phone":                MaskPhone,
+		"clientFirstName":      RedactAll,
+		"clientLastName":       RedactAll,
+		"recipientFirstName":   RedactAll,
+		"recipientLastName":    RedactAll,
+		"clientIpAddress":      RedactAll,
+	}
+}
+
+// RedactAll hides the whole value.
+func RedactAll(string) string {
+	return Redacted
+}
+
+// MaskPAN keeps the first 6 and the last 4 characters of a card number longer
+// than 10 characters and only the last 4 of a shorter one.
+func MaskPAN(pan string) string {
+	if len(pan) > 10 {
+		return pan[:6] + strings.Repeat("*", len(pan)-10) + pan[len(pan)-4:]
+	}
+	return maskTail(pan, 4)
+}
+
+// MaskEmail keeps the first character of the local part and the domain.
+func MaskEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 1 {
+		return Redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// MaskPhone keeps the last 4 digits.
+func MaskPhone(phone string) string {
+	return maskTail(phone, 4)
+}
+
+func maskTail(v string, keep int) string {
+	if len(v) <= keep {
+		return strings.Repeat("*", len(v))
+	}
+	return strings.Repeat("*", len(v)-keep) + v[len(v)-keep:]
+}
+
+// redact returns body with the fields of the policy redacted. A body that is
+// not JSON is never logged as is.
+func (p RedactionPolicy) redact(body []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return Redacted
+	}
+	out, err := json.Marshal(p.walk(v))
+	if err != nil {
+		return Redacted
+	}
+	return string(out)
+}
+
+func (p RedactionPolicy) walk(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if redaction, ok := p[key]; ok {
+				v[key] = redactValue(redaction, value)
+			} else {
+				v[key] = p.walk(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = p.walk(value)
+		}
+	}
+	return v
+}
+
+func redactValue(redaction Redaction, v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return v
+		}
+		return redaction(v)
+	case json.Number:
+		return redaction(v.String())
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(redaction, value)
+		}
+		return v
+	}
+	return Redacted
+}
+
+// logCall logs a call once it is answered: the transaction type, order
+// reference, latency and reason code at the success or failure level and,
+// when the logger is enabled for debug, the redacted request and response.
+func (w *WayForPay) logCall(ctx context.Context, call *Call, response Responder, next Invoker) error {
+	start := time.Now()
+	err := next(ctx, call, response)
+
+	attrs := []slog.Attr{
+		slog.String("transactionType", call.TransactionType),
+		slog.String("orderReference", call.OrderReference),
+		slog.Duration("latency", time.Since(start)),
+	}
+	level := w.logLevel
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		// The raw body snippet is left out: it cannot be redacted reliably.
+		attrs = append(attrs, slog.Int("httpStatus", transportErr.StatusCode))
+		if transportErr.ContentType != "" {
+			attrs = append(attrs, slog.String("contentType", transportErr.ContentType))
+		}
+	}
+	if code := reasonCode(response, err); code != 0 {
+		attrs = append(attrs, slog.Int("reasonCode", code))
+	}
+	if call.attempts > 1 {
+		attrs = append(attrs, slog.Int("attempts", call.attempts))
+	}
+	if err != nil {
+		level = w.errorLogLevel
+		cause := err
+		if transportErr != nil && transportErr.Err != nil {
+			cause = transportErr.Err
+		}
+		attrs = append(attrs, slog.String("error", cause.Error()))
+	}
+	if w.logger.Enabled(ctx, slog.LevelDebug) {
+		if body, marshalErr := json.Marshal(call.payment); marshalErr == nil {
+			attrs = append(attrs, slog.String("request", w.redaction.redact(body)))
+		}
+		if transportErr == nil {
+			if body, marshalErr := json.Marshal(response); marshalErr == nil {
+				attrs = append(attrs, slog.String("response", w.redaction.redact(body)))
+			}
+		}
+	}
+	w.logger.LogAttrs(ctx, level, "wayforpay call", attrs...)
+	return err
+}
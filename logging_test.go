@@ -0,0 +1,134 @@
+package wayforpay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_Logging(t *testing.T) {
+	ctx := context.Background()
+	records := func(buf *bytes.Buffer) []map[string]any {
+		var out []map[string]any
+		dec := json.NewDecoder(buf)
+		for dec.More() {
+			var record map[string]any
+			require.NoError(t, dec.Decode(&record))
+			out = append(out, record)
+		}
+		return out
+	}
+
+	t.Run("create invoice", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		t.Cleanup(srv.Close)
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret, wfp.WithLogger(logger))
+		require.NoError(t, err)
+
+		_, err = wfpClient.CreateInvoiceContext(ctx, wfpClient.NewCreateInvoiceRequest().
+			SetMerchantDomainName("test.com").
+			SetOrderDate(time.Now()).
+			SetMoney(wfp.NewMoney(wfp.MustParseAmount("100"), "UAH")).
+			SetOrderReference("ORDER-1").
+			SetClientFirstName("Taras").
+			SetClientEmail("taras@example.com").
+			SetClientPhone("380501234567").
+			AddProduct("test", wfp.MustParseAmount("100"), 1))
+		require.NoError(t, err)
+		srv.FailNext("CHECK_STATUS", 1131, "In processing")
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.Error(t, err)
+
+		logged := records(&buf)
+		require.Len(t, logged, 2)
+		require.Equal(t, "INFO", logged[0]["level"])
+		require.Equal(t, "CREATE_INVOICE", logged[0]["transactionType"])
+		require.Equal(t, "ORDER-1", logged[0]["orderReference"])
+		require.EqualValues(t, wfp.ReasonCodeOk, logged[0]["reasonCode"])
+		require.Contains(t, logged[0], "latency")
+
+		var request map[string]any
+		require.NoError(t, json.Unmarshal([]byte(logged[0]["request"].(string)), &request))
+		require.Equal(t, wfp.Redacted, request["merchantSignature"])
+		require.Equal(t, wfp.Redacted, request["clientFirstName"])
+		require.Equal(t, "t***@example.com", request["clientEmail"])
+		require.Equal(t, "********4567", request["clientPhone"])
+		require.Equal(t, []any{"test"}, request["productName"])
+		require.Contains(t, logged[0], "response")
+
+		require.Equal(t, "WARN", logged[1]["level"])
+		require.EqualValues(t, 1131, logged[1]["reasonCode"])
+		require.Contains(t, logged[1]["error"], "In processing")
+	})
+
+	t.Run("check status", func(t *testing.T) {
+		answer := `{"merchantAccount":"test_merch_n1","orderReference":"ORDER-1","merchantSignature":"abcdef",` +
+			`"amount":100,"currency":"UAH","cardPan":"4111111111111111","recToken":"tok-secret",` +
+			`"transactionStatus":"Approved","reasonCode":1100,"reason":"Ok"}`
+		httpClient := &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(answer)),
+					Request:    req,
+				}, nil
+			}),
+		}
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		wfpClient, err := wfp.NewClient(httpClient, merchantLogin, merchantSecret,
+			wfp.WithLogger(logger), wfp.WithLogLevels(slog.LevelDebug, slog.LevelError))
+		require.NoError(t, err)
+
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.NoError(t, err)
+		require.NotContains(t, buf.String(), "4111111111111111")
+		require.NotContains(t, buf.String(), "tok-secret")
+		require.NotContains(t, buf.String(), "abcdef")
+
+		logged := records(&buf)
+		require.Len(t, logged, 1)
+		require.Equal(t, "DEBUG", logged[0]["level"])
+		var response map[string]any
+		require.NoError(t, json.Unmarshal([]byte(logged[0]["response"].(string)), &response))
+		require.Equal(t, "411111******1111", response["cardPan"])
+		require.Equal(t, wfp.Redacted, response["recToken"])
+		require.Equal(t, "Approved", response["transactionStatus"])
+	})
+
+	t.Run("bodies are left out above debug", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		t.Cleanup(srv.Close)
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret, wfp.WithLogger(logger))
+		require.NoError(t, err)
+
+		srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html>maintenance</html>")
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.Error(t, err)
+
+		logged := records(&buf)
+		require.Len(t, logged, 1)
+		require.EqualValues(t, http.StatusServiceUnavailable, logged[0]["httpStatus"])
+		require.Equal(t, "text/html", logged[0]["contentType"])
+		require.NotContains(t, logged[0], "request")
+		require.NotContains(t, buf.String(), "maintenance")
+	})
+
+	require.Equal(t, "j***@x.io", wfp.MaskEmail("john@x.io"))
+	require.Equal(t, wfp.Redacted, wfp.MaskEmail("not-an-email"))
+	require.Equal(t, "******1111", wfp.MaskPAN("41****1111"))
+}
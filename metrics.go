@@ -0,0 +1,80 @@
+package wayforpay
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Histogram and Counter are the operations PrometheusObserver needs from
+// labelled collectors, so the SDK does not depend on a metrics library. The
+// wayforpayprom module provides them over Prometheus client_golang.
+type Histogram interface {
+	Observe(value float64, labelValues ...string)
+}
+
+// Counter is a labelled counter, see Histogram.
+type Counter interface {
+	Add(value float64, labelValues ...string)
+}
+
+// Label names used by PrometheusObserver, in the order label values are passed.
+var (
+	// LatencyLabels label Latency: transaction_type, outcome.
+	LatencyLabels = []string{"transaction_type", "outcome"}
+	// CallLabels label Calls: transaction_type, http_status, reason_code.
+	CallLabels = []string{"transaction_type", "http_status", "reason_code"}
+	// RetryLabels label Retries: transaction_type.
+	RetryLabels = []string{"transaction_type"}
+)
+
+// Outcomes reported in the outcome label of Latency.
+const (
+	OutcomeSuccess   = "success"
+	OutcomeDeclined  = "declined"
+	OutcomeTransport = "transport_error"
+	OutcomeError     = "error"
+)
+
+// PrometheusObserver reports calls to Prometheus-style collectors. Nil
+// collectors are skipped.
+type PrometheusObserver struct {
+	// Latency observes the call duration in seconds, e.g.
+	// wayforpay_call_duration_seconds.
+	Latency Histogram
+	// Calls counts calls, e.g. wayforpay_calls_total.
+	Calls Counter
+	// Retries counts attempts after the first one, e.g. wayforpay_retries_total.
+	Retries Counter
+}
+
+func (p PrometheusObserver) Start(ctx context.Context, _ *Call) context.Context {
+	return ctx
+}
+
+func (p PrometheusObserver) Finish(_ context.Context, o Observation) {
+	if p.Latency != nil {
+		p.Latency.Observe(o.Latency.Seconds(), o.TransactionType, outcome(o))
+	}
+	if p.Calls != nil {
+		p.Calls.Add(1, o.TransactionType, strconv.Itoa(o.HTTPStatus), strconv.Itoa(o.ReasonCode))
+	}
+	if p.Retries != nil && o.Retries() > 0 {
+		p.Retries.Add(float64(o.Retries()), o.TransactionType)
+	}
+}
+
+// outcome classifies the observation for the outcome label.
+func outcome(o Observation) string {
+	var apiErr *APIError
+	var transportErr *TransportError
+	switch {
+	case o.Err == nil:
+		return OutcomeSuccess
+	case errors.As(o.Err, &apiErr):
+		return OutcomeDeclined
+	case errors.As(o.Err, &transportErr):
+		return OutcomeTransport
+	}
+	return OutcomeError
+}
@@ -0,0 +1,206 @@
+package wayforpay
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Amount is an exact decimal amount of money with two fractional digits
+// (kopecks, cents). The zero value is 0.
+//
+// String returns the canonical form used both in the merchantSignature and in
+// JSON, so the signed and the sent amount never differ: no trailing zeros and
+// no decimal point for whole amounts ("100", "80.5", "10.25").
+type Amount struct {
+	minor int64
+}
+
+// NewAmount returns the amount of minor units (kopecks, cents).
+func NewAmount(minor int64) Amount {
+	return Amount{minor: minor}
+}
+
+// amountPattern is the grammar of ParseAmount: whole units without leading
+// zeros, optionally followed by one or two fractional digits.
+var amountPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.[0-9]{1,2})?$`)
+
+// ParseAmount parses a non-negative decimal amount such as "100", "80.5" or
+// "80.50". Signs, exponents, separators, leading zeros and more than two
+// fractional digits are rejected.
+func ParseAmount(s string) (Amount, error) {
+	if !amountPattern.MatchString(s) {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, s)
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > (math.MaxInt64-99)/100 {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, s)
+	}
+	cents, _ := strconv.ParseInt((frac + "00")[:2], 10, 64)
+	return Amount{minor: units*100 + cents}, nil
+}
+
+// MustParseAmount is like ParseAmount but panics on a malformed amount.
+// Use it for constants.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Minor returns the amount in minor units (kopecks, cents).
+func (a Amount) Minor() int64 {
+	return a.minor
+}
+
+func (a Amount) IsZero() bool {
+	return a.minor == 0
+}
+
+func (a Amount) Add(b Amount) Amount {
+	return Amount{minor: a.minor + b.minor}
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{minor: a.minor - b.minor}
+}
+
+// Mul returns the amount multiplied by n, e.g. a unit price by a quantity.
+func (a Amount) Mul(n int64) Amount {
+	return Amount{minor: a.minor * n}
+}
+
+// Cmp returns -1, 0 or +1 when a is less than, equal to or greater than b.
+func (a Amount) Cmp(b Amount) int {
+	switch {
+	case a.minor < b.minor:
+		return -1
+	case a.minor > b.minor:
+		return 1
+	}
+	return 0
+}
+
+// Float64 returns the nearest float64 value. Do not use it for arithmetic.
+func (a Amount) Float64() float64 {
+	return float64(a.minor) / 100
+}
+
+// String returns the canonical decimal form of the amount.
+func (a Amount) String() string {
+	minor, sign := uint64(a.minor), ""
+	if a.minor < 0 {
+		minor, sign = -minor, "-"
+	}
+	units, cents := minor/100, minor%100
+	switch {
+	case cents == 0:
+		return sign + strconv.FormatUint(units, 10)
+	case cents%10 == 0:
+		return fmt.Sprintf("%s%d.%d", sign, units, cents/10)
+	default:
+		return fmt.Sprintf("%s%d.%02d", sign, units, cents)
+	}
+}
+
+// MarshalJSON encodes the amount as a JSON number in its canonical form.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts the amount as a JSON number or a string in any
+// decimal or exponent form ("100", "100.000", "1.0E2"), with an optional
+// minus sign so that every Amount survives a round trip. Trailing zeros are
+// dropped, but an amount finer than minor units is rejected. An empty string
+// and null decode to zero.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	v, err := decodeAmount(data, false)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// jsonFee decodes a fee, which WayForPay may compute finer than minor units
+// ("1.125"). The fee is informational and never signed, so extra fractional
+// digits are rounded half away from zero instead of failing the answer.
+type jsonFee Amount
+
+func (f *jsonFee) UnmarshalJSON(data []byte) error {
+	v, err := decodeAmount(data, true)
+	if err != nil {
+		return err
+	}
+	*f = jsonFee(v)
+	return nil
+}
+
+// decodeAmount decodes a JSON amount. Digits finer than minor units are
+// rounded when round is set and rejected otherwise.
+func decodeAmount(data []byte, round bool) (Amount, error) {
+	raw := string(bytes.Trim(data, `"`))
+	if raw == "" || raw == "null" {
+		return Amount{}, nil
+	}
+	r, ok := parseDecimal(raw)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, raw)
+	}
+	minor, ok := scaleRat(r, 100)
+	if !ok || !round && big.NewRat(minor, 100).Cmp(r) != 0 {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, raw)
+	}
+	return Amount{minor: minor}, nil
+}
+
+// orNil returns nil for a zero amount, for optional fields tagged omitempty.
+func (a Amount) orNil() *Amount {
+	if a.IsZero() {
+		return nil
+	}
+	return &a
+}
+
+// Money is an amount in an ISO 4217 currency. Requests and responses embed it,
+// so it encodes as the "amount" and "currency" fields of the enclosing object.
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// NewMoney returns the amount in currency.
+func NewMoney(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses the amount with ParseAmount and returns it in currency.
+func ParseMoney(amount, currency string) (Money, error) {
+	a, err := ParseAmount(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(a, currency), nil
+}
+
+// money lets requests that embed Money report it to interceptors.
+func (m Money) money() Money {
+	return m
+}
+
+func (m Money) check() error {
+	if m.Amount.minor <= 0 {
+		return ErrAmountRequired
+	}
+	if m.Currency == "" {
+		return ErrCurrencyRequired
+	}
+	return nil
+}
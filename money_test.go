@@ -0,0 +1,126 @@
+package wayforpay_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "600.00", want: "600"},
+		{in: "600.0", want: "600"},
+		{in: "80.50", want: "80.5"},
+		{in: "10.25", want: "10.25"},
+		{in: "0.07", want: "0.07"},
+		{in: "0", want: "0"},
+		{in: "92233720368547757", want: "92233720368547757"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := wfp.ParseAmount(tt.in)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+
+	rejected := []struct {
+		name string
+		in   string
+	}{
+		{name: "empty", in: ""},
+		{name: "letters", in: "abc"},
+		{name: "fraction", in: "1/3"},
+		{name: "hex", in: "0x10"},
+		{name: "hex float", in: "0x1p4"},
+		{name: "exponent", in: "1e2"},
+		{name: "underscore", in: "1_000"},
+		{name: "leading zero", in: "0100"},
+		{name: "leading zeros before point", in: "00.5"},
+		{name: "three fractional digits", in: "10.005"},
+		{name: "trailing zero beyond cents", in: "10.500"},
+		{name: "bare point", in: "10."},
+		{name: "no units", in: ".5"},
+		{name: "minus", in: "-3.10"},
+		{name: "plus", in: "+3"},
+		{name: "spaces", in: " 10 "},
+		{name: "comma", in: "10,50"},
+		{name: "overflow", in: "92233720368547758"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := wfp.ParseAmount(tt.in)
+			require.ErrorIs(t, err, wfp.ErrMalformedAmount)
+		})
+	}
+
+	require.Equal(t, wfp.NewAmount(1050), wfp.MustParseAmount("7.50").Mul(2).Sub(wfp.MustParseAmount("4.5")))
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var got struct {
+		wfp.Money
+		Fee wfp.Amount `json:"fee"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"600.50","currency":"UAH","fee":12.3}`), &got))
+	require.Equal(t, wfp.NewMoney(wfp.NewAmount(60050), "UAH"), got.Money)
+	require.Equal(t, wfp.NewAmount(1230), got.Fee)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":600.5,"currency":"UAH","fee":""}`), &got))
+	require.Equal(t, wfp.NewAmount(60050), got.Amount)
+	require.True(t, got.Fee.IsZero())
+
+	body, err := json.Marshal(got)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":600.5,"currency":"UAH","fee":0}`, string(body))
+
+	got.Fee = wfp.NewAmount(-310)
+	body, err = json.Marshal(got)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(body, &got))
+	require.Equal(t, wfp.NewAmount(-310), got.Fee)
+
+	for raw, want := range map[string]wfp.Amount{
+		`"100.000"`: wfp.NewAmount(10000),
+		`1.0E2`:     wfp.NewAmount(10000),
+		`"1e-2"`:    wfp.NewAmount(1),
+		`-0.50`:     wfp.NewAmount(-50),
+	} {
+		require.NoError(t, json.Unmarshal([]byte(`{"amount":`+raw+`}`), &got), raw)
+		require.Equal(t, want, got.Amount, raw)
+	}
+	for _, raw := range []string{`"1.125"`, `"0x10"`, `"1_000"`, `"1/2"`, `"1,5"`} {
+		require.ErrorIs(t, json.Unmarshal([]byte(`{"amount":`+raw+`}`), &got), wfp.ErrMalformedAmount, raw)
+	}
+}
+
+func TestAmount_OmittedWhenZero(t *testing.T) {
+	wfpClient, err := wfp.NewClient(nil, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		request any
+		field   string
+		want    any
+	}{
+		{name: "invoice without alternative amount", request: wfpClient.NewCreateInvoiceRequest(), field: "alternativeAmount"},
+		{name: "invoice with alternative amount", request: wfpClient.NewCreateInvoiceRequest().SetAlternativeAmount(wfp.MustParseAmount("10.5")), field: "alternativeAmount", want: 10.5},
+		{name: "regular without amount", request: wfpClient.RegularPayments().NewCreateRequest("AAA"), field: "amount"},
+		{name: "regular with amount", request: wfpClient.RegularPayments().NewCreateRequest("AAA").SetAmount(wfp.MustParseAmount("100")), field: "amount", want: 100.0},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.request)
+			require.NoError(t, err)
+			var fields map[string]any
+			require.NoError(t, json.Unmarshal(body, &fields))
+			require.Equal(t, tt.want, fields[tt.field])
+		})
+	}
+}
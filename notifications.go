@@ -0,0 +1,199 @@
+package wayforpay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ResponseStatusAccept acknowledges a serviceUrl notification.
+const ResponseStatusAccept = "accept"
+
+// maxNotificationSize limits the body of a serviceUrl notification.
+const maxNotificationSize = 1 << 20
+
+// Notification is the transaction state WayForPay posts to the serviceUrl.
+type Notification struct {
+	MerchantAccount   string `json:"merchantAccount"`
+	OrderReference    string `json:"orderReference"`
+	MerchantSignature string `json:"merchantSignature"`
+	Money
+	AuthCode          string    `json:"authCode"`
+	Email             string    `json:"email"`
+	Phone             string    `json:"phone"`
+	CreatedDate       time.Time `json:"createdDate"`
+	ProcessingDate    time.Time `json:"processingDate"`
+	CardPan           string    `json:"cardPan"`
+	CardType          string    `json:"cardType"`
+	IssuerBankCountry string    `json:"issuerBankCountry"`
+	IssuerBankName    string    `json:"issuerBankName"`
+	RecToken          string    `json:"recToken"`
+	PaymentSystem     string    `json:"paymentSystem"`
+	TransactionStatus string    `json:"transactionStatus"`
+	Reason            string    `json:"reason"`
+	ReasonCode        int       `json:"reasonCode"`
+	Fee               Amount    `json:"fee"`
+}
+
+// UnmarshalJSON decodes the notification, accepting numbers sent as strings.
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	type alias Notification
+	aux := struct {
+		*alias
+		CreatedDate    jsonTime `json:"createdDate"`
+		ProcessingDate jsonTime `json:"processingDate"`
+		ReasonCode     jsonInt  `json:"reasonCode"`
+		Fee            jsonFee  `json:"fee"`
+	}{alias: (*alias)(n)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	n.CreatedDate = time.Time(aux.CreatedDate)
+	n.ProcessingDate = time.Time(aux.ProcessingDate)
+	n.ReasonCode = int(aux.ReasonCode)
+	n.Fee = Amount(aux.Fee)
+	return nil
+}
+
+// notificationSignatureFields lists the signed notification fields, in order.
+var notificationSignatureFields = []string{
+	"merchantAccount",
+	"orderReference",
+	"amount",
+	"currency",
+	"authCode",
+	"cardPan",
+	"transactionStatus",
+	"reasonCode",
+}
+
+// rawFields keeps the textual form of every field, exactly as it was signed.
+type rawFields map[string]string
+
+func (f rawFields) sign(secret string, fields []string) string {
+	data := make([]string, 0, len(fields))
+	for _, field := range fields {
+		data = append(data, f[field])
+	}
+	return signature(secret, data)
+}
+
+// decodeCallback reads a JSON or form-encoded callback body. WayForPay may post
+// the JSON document as the only key of a form-encoded body, which is handled too.
+func decodeCallback(r *http.Request) (rawFields, []byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxNotificationSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) > maxNotificationSize {
+		return nil, nil, ErrNotificationTooLarge
+	}
+	body = bytes.TrimSpace(body)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" && !bytes.HasPrefix(body, []byte("{")) {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(values) == 1 {
+			for key, value := range values {
+				if strings.HasPrefix(key, "{") && (len(value) == 0 || value[0] == "") {
+					body = []byte(key)
+				}
+			}
+		}
+		if !bytes.HasPrefix(body, []byte("{")) {
+			fields := rawFields{}
+			for key := range values {
+				fields[key] = values.Get(key)
+			}
+			encoded, err := json.Marshal(fields)
+			if err != nil {
+				return nil, nil, err
+			}
+			return fields, encoded, nil
+		}
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, err
+	}
+	fields := rawFields{}
+	for key, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			fields[key] = s
+			continue
+		}
+		fields[key] = string(value)
+	}
+	return fields, body, nil
+}
+
+// ParseNotification decodes a serviceUrl notification and verifies its merchantSignature.
+func (w *WayForPay) ParseNotification(r *http.Request) (*Notification, error) {
+	fields, body, err := decodeCallback(r)
+	if err != nil {
+		return nil, err
+	}
+	expected := fields.sign(w.merchantSecret, notificationSignatureFields)
+	if !hmac.Equal([]byte(expected), []byte(fields["merchantSignature"])) {
+		return nil, ErrInvalidNotificationSignature
+	}
+	if fields["merchantAccount"] != w.merchantLogin {
+		return nil, ErrMerchantAccountMismatch
+	}
+	var n Notification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// NotificationHandlerFunc processes a verified notification. Returning an error
+// refuses the notification, so WayForPay delivers it again later.
+type NotificationHandlerFunc func(ctx context.Context, n *Notification) error
+
+// NotificationHandler returns an http.Handler for the serviceUrl. It verifies every
+// notification, passes it to fn and answers with the signed accept Response.
+func (w *WayForPay) NotificationHandler(fn NotificationHandlerFunc) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		n, err := w.ParseNotification(r)
+		if err != nil {
+			w.logNotificationError(r.Context(), "wayforpay notification rejected", "", err)
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if err := fn(r.Context(), n); err != nil {
+			w.logNotificationError(r.Context(), "wayforpay notification refused", n.OrderReference, err)
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(w.NewResponse(n.OrderReference, ResponseStatusAccept, w.now().Unix()))
+	})
+}
+
+func (w *WayForPay) logNotificationError(ctx context.Context, msg, orderReference string, err error) {
+	if w.logger == nil {
+		return
+	}
+	w.logger.LogAttrs(ctx, w.errorLogLevel, msg,
+		slog.String("orderReference", orderReference),
+		slog.String("error", err.Error()),
+	)
+}
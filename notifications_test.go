@@ -0,0 +1,89 @@
+package wayforpay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_NotificationHandler(t *testing.T) {
+	signature := sign(merchantSecret, merchantLogin, "AAA", "100.50", "UAH", "541963", "41****8217", "Approved", "1100")
+	jsonBody := `{"merchantAccount":"test_merch_n1","orderReference":"AAA","merchantSignature":"` + signature + `",` +
+		`"amount":100.50,"currency":"UAH","authCode":"541963","cardPan":"41****8217","transactionStatus":"Approved",` +
+		`"reasonCode":1100,"reason":"Ok","createdDate":1700000000,"processingDate":1700000060,"recToken":"tok"}`
+	form := url.Values{
+		"merchantAccount":   {merchantLogin},
+		"orderReference":    {"AAA"},
+		"merchantSignature": {signature},
+		"amount":            {"100.50"},
+		"currency":          {"UAH"},
+		"authCode":          {"541963"},
+		"cardPan":           {"41****8217"},
+		"transactionStatus": {"Approved"},
+		"reasonCode":        {"1100"},
+		"createdDate":       {"1700000000"},
+		"recToken":          {"tok"},
+	}
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		handlerErr  error
+		wantStatus  int
+	}{
+		{name: "json", contentType: "application/json", body: jsonBody, wantStatus: http.StatusOK},
+		{name: "form fields", contentType: "application/x-www-form-urlencoded", body: form.Encode(), wantStatus: http.StatusOK},
+		{name: "json as form key", contentType: "application/x-www-form-urlencoded", body: url.QueryEscape(jsonBody), wantStatus: http.StatusOK},
+		{name: "fee finer than kopecks", contentType: "application/json", body: strings.Replace(jsonBody, `"recToken"`, `"fee":"1.125","recToken"`, 1), wantStatus: http.StatusOK},
+		{name: "invalid signature", contentType: "application/json", body: strings.Replace(jsonBody, "100.50", "1.50", 1), wantStatus: http.StatusBadRequest},
+		{name: "oversized", contentType: "application/json", body: jsonBody + strings.Repeat(" ", 1<<20), wantStatus: http.StatusBadRequest},
+		{name: "handler refuses", contentType: "application/json", body: jsonBody, handlerErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			wfpClient, err := wfp.NewClient(nil, merchantLogin, merchantSecret,
+				wfp.WithClock(func() time.Time { return time.Unix(123456789, 0) }),
+				wfp.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+				wfp.WithLogLevels(slog.LevelInfo, slog.LevelError))
+			require.NoError(t, err)
+
+			var got *wfp.Notification
+			handler := wfpClient.NotificationHandler(func(ctx context.Context, n *wfp.Notification) error {
+				got = n
+				return tt.handlerErr
+			})
+			req := httptest.NewRequest(http.MethodPost, "/callback/wfp", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				require.Contains(t, logs.String(), `"level":"ERROR"`)
+				return
+			}
+			require.Equal(t, "AAA", got.OrderReference)
+			require.Equal(t, wfp.MustParseAmount("100.5"), got.Amount)
+			require.Equal(t, 1100, got.ReasonCode)
+			require.Equal(t, "tok", got.RecToken)
+			require.Equal(t, time.Unix(1700000000, 0), got.CreatedDate)
+
+			var resp wfp.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Equal(t, wfpClient.NewResponse("AAA", wfp.ResponseStatusAccept, 123456789), &resp)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package wayforpay
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Observation is the outcome of a call reported to an Observer.
+type Observation struct {
+	TransactionType string
+	OrderReference  string
+	// Latency covers the whole call: interceptors, retries and backoff.
+	Latency time.Duration
+	// HTTPStatus is the status of the last answer, 0 when none was received.
+	HTTPStatus int
+	// ReasonCode is the WayForPay reason code of the answer, 0 when none was decoded.
+	ReasonCode int
+	// Attempts is the number of times the request was sent, 0 when it was not
+	// sent at all, e.g. because it failed validation.
+	Attempts int
+	Err      error
+}
+
+// Retries returns the number of attempts after the first one.
+func (o Observation) Retries() int {
+	return max(o.Attempts-1, 0)
+}
+
+// Observer receives every call made by the client, for metrics and tracing.
+type Observer interface {
+	// Start is called before the interceptors run. The returned context is
+	// used for the call and passed to Finish, e.g. to carry a span.
+	Start(ctx context.Context, call *Call) context.Context
+	// Finish is called once the call returns.
+	Finish(ctx context.Context, observation Observation)
+}
+
+// NopObserver ignores every call. It is the default Observer.
+type NopObserver struct{}
+
+func (NopObserver) Start(ctx context.Context, _ *Call) context.Context { return ctx }
+
+func (NopObserver) Finish(context.Context, Observation) {}
+
+// MultiObserver reports every call to all observers, in order.
+func MultiObserver(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) Start(ctx context.Context, call *Call) context.Context {
+	for _, o := range m {
+		ctx = o.Start(ctx, call)
+	}
+	return ctx
+}
+
+func (m multiObserver) Finish(ctx context.Context, observation Observation) {
+	for _, o := range m {
+		o.Finish(ctx, observation)
+	}
+}
+
+// reasonCode returns the reason code of a declined call or of the decoded answer.
+func reasonCode(response Responder, err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ReasonCode
+	}
+	return response.GetReasonCode()
+}
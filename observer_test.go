@@ -0,0 +1,150 @@
+package wayforpay_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMetric is an in-memory labelled collector.
+type memoryMetric struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func (m *memoryMetric) record(value float64, labels []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = map[string][]float64{}
+	}
+	key := strings.Join(labels, ",")
+	m.values[key] = append(m.values[key], value)
+}
+
+func (m *memoryMetric) Observe(value float64, labels ...string) { m.record(value, labels) }
+
+func (m *memoryMetric) Add(value float64, labels ...string) { m.record(value, labels) }
+
+// memoryTracer exports finished spans to memory.
+type memoryTracer struct {
+	spans []*memorySpan
+}
+
+type memorySpan struct {
+	name       string
+	attributes map[string]any
+	errs       []error
+	ended      bool
+}
+
+func (t *memoryTracer) StartSpan(ctx context.Context, name string) (context.Context, wfp.Span) {
+	span := &memorySpan{name: name, attributes: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *memorySpan) SetAttribute(key string, value any) { s.attributes[key] = value }
+
+func (s *memorySpan) RecordError(err error) { s.errs = append(s.errs, err) }
+
+func (s *memorySpan) End() { s.ended = true }
+
+type recordingObserver struct {
+	observations []wfp.Observation
+}
+
+func (r *recordingObserver) Start(ctx context.Context, _ *wfp.Call) context.Context { return ctx }
+
+func (r *recordingObserver) Finish(_ context.Context, o wfp.Observation) {
+	r.observations = append(r.observations, o)
+}
+
+func TestWayForPay_Observer(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	latency, calls, retries := &memoryMetric{}, &memoryMetric{}, &memoryMetric{}
+	tracer, other := &memoryTracer{}, &memoryTracer{}
+	recorder := &recordingObserver{}
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret,
+		wfp.WithRetryPolicy(wfp.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		wfp.WithObserver(wfp.MultiObserver(
+			wfp.PrometheusObserver{Latency: latency, Calls: calls, Retries: retries},
+			&wfp.TracingObserver{Tracer: tracer},
+			&wfp.TracingObserver{Tracer: other},
+			recorder,
+		)))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	srv.FailNext("CHECK_STATUS", 1131, "In processing")
+	_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+	require.ErrorIs(t, err, wfp.ErrOrderNotFound)
+
+	srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html></html>")
+	srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html></html>")
+	srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html></html>")
+	_, err = wfpClient.CheckStatus(ctx, "ORDER-2")
+	require.ErrorIs(t, err, wfp.ErrUnexpectedStatus)
+
+	_, err = wfpClient.CreateInvoiceContext(ctx, wfpClient.NewCreateInvoiceRequest())
+	require.Error(t, err)
+
+	require.Len(t, recorder.observations, 3)
+	first := recorder.observations[0]
+	require.Equal(t, "CHECK_STATUS", first.TransactionType)
+	require.Equal(t, "ORDER-1", first.OrderReference)
+	require.Equal(t, http.StatusOK, first.HTTPStatus)
+	require.Equal(t, 1127, first.ReasonCode)
+	require.Equal(t, 2, first.Attempts)
+	require.Equal(t, 1, first.Retries())
+	require.Positive(t, first.Latency)
+	require.Equal(t, http.StatusServiceUnavailable, recorder.observations[1].HTTPStatus)
+	require.Equal(t, 3, recorder.observations[1].Attempts)
+	require.Zero(t, recorder.observations[2].Attempts)
+
+	require.Len(t, latency.values["CHECK_STATUS,declined"], 1)
+	require.Len(t, latency.values["CHECK_STATUS,transport_error"], 1)
+	require.Len(t, latency.values["CREATE_INVOICE,error"], 1)
+	require.Equal(t, []float64{1}, calls.values["CHECK_STATUS,200,1127"])
+	require.Equal(t, []float64{1}, calls.values["CHECK_STATUS,503,0"])
+	require.Equal(t, []float64{1, 2}, retries.values["CHECK_STATUS"])
+
+	require.Len(t, tracer.spans, 3)
+	span := tracer.spans[0]
+	require.Equal(t, "wayforpay CHECK_STATUS", span.name)
+	require.True(t, span.ended)
+	require.Equal(t, map[string]any{
+		wfp.AttrTransactionType: "CHECK_STATUS",
+		wfp.AttrOrderReference:  "ORDER-1",
+		wfp.AttrHTTPStatus:      http.StatusOK,
+		wfp.AttrReasonCode:      1127,
+		wfp.AttrAttempts:        2,
+	}, span.attributes)
+	require.Len(t, span.errs, 1)
+	require.ErrorIs(t, span.errs[0], wfp.ErrOrderNotFound)
+
+	// Each observer ends its own span.
+	require.Len(t, other.spans, 3)
+	for i, span := range other.spans {
+		require.True(t, span.ended)
+		require.Equal(t, tracer.spans[i].attributes, span.attributes)
+	}
+}
+
+func TestWithObserver_Nil(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret, wfp.WithObserver(nil))
+	require.NoError(t, err)
+
+	_, err = wfpClient.CheckStatus(context.Background(), "ORDER-1")
+	require.ErrorIs(t, err, wfp.ErrOrderNotFound)
+}
@@ -0,0 +1,149 @@
+package wayforpay
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Option configures the WayForPay client.
+type Option func(*WayForPay)
+
+// WithBaseURL sets the API base URL. Default: DefaultBaseURL
+func WithBaseURL(baseURL string) Option {
+	return func(w *WayForPay) {
+		w.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithRegularURL sets the regular payments API url. Default: DefaultRegularURL
+func WithRegularURL(regularURL string) Option {
+	return func(w *WayForPay) {
+		w.regularURL = strings.TrimRight(regularURL, "/")
+	}
+}
+
+// WithMerchantPassword sets the merchant password required by the regular payments API.
+func WithMerchantPassword(merchantPassword string) Option {
+	return func(w *WayForPay) {
+		w.merchantPassword = merchantPassword
+	}
+}
+
+// WithAuthorizationTicket sets the merchant authorization ticket that
+// authorizes COMPLETE_3DS requests in place of a signature.
+func WithAuthorizationTicket(ticket string) Option {
+	return func(w *WayForPay) {
+		w.authorizationTicket = ticket
+	}
+}
+
+// WithPurchaseURL sets the hosted payment page url. Default: DefaultPurchaseURL
+func WithPurchaseURL(purchaseURL string) Option {
+	return func(w *WayForPay) {
+		w.purchaseURL = purchaseURL
+	}
+}
+
+// WithVerifyURL sets the card verification page url. Default: DefaultVerifyURL
+func WithVerifyURL(verifyURL string) Option {
+	return func(w *WayForPay) {
+		w.verifyURL = verifyURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. Default: DefaultUserAgent
+func WithUserAgent(userAgent string) Option {
+	return func(w *WayForPay) {
+		w.userAgent = userAgent
+	}
+}
+
+// WithAPIVersion sets the apiVersion used by new requests. Default: DefaultAPIVersion
+func WithAPIVersion(apiVersion int) Option {
+	return func(w *WayForPay) {
+		w.apiVersion = apiVersion
+	}
+}
+
+// WithLanguage sets the language used by new requests. Default: DefaultLanguage
+// Possible values: RU, UA, EN
+func WithLanguage(language string) Option {
+	return func(w *WayForPay) {
+		w.language = language
+	}
+}
+
+// WithClock sets the time source. Default: time.Now
+func WithClock(now func() time.Time) Option {
+	return func(w *WayForPay) {
+		w.now = now
+	}
+}
+
+// WithLogger sets the logger every call is logged to, see WithLogLevels and
+// WithRedactionPolicy. Default: no logging
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *WayForPay) {
+		w.logger = logger
+	}
+}
+
+// WithCurrencyRatesTTL caches the exchange rates returned by CurrencyRates for ttl.
+// Default: no caching
+func WithCurrencyRatesTTL(ttl time.Duration) Option {
+	return func(w *WayForPay) {
+		w.ratesTTL = ttl
+	}
+}
+
+// WithRetryPolicy repeats failed calls according to policy. Default: a single attempt
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(w *WayForPay) {
+		w.retry = policy
+	}
+}
+
+// WithMaxResponseSize limits the answer body read from WayForPay to size bytes.
+// A larger answer fails with ErrResponseTooLarge. Default: DefaultMaxResponseSize
+func WithMaxResponseSize(size int64) Option {
+	return func(w *WayForPay) {
+		w.maxResponseSize = size
+	}
+}
+
+// WithInterceptors adds interceptors run around every call, in the given order:
+// the first one sees the call first and the answer last.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(w *WayForPay) {
+		w.interceptors = append(w.interceptors, interceptors...)
+	}
+}
+
+// WithLogLevels sets the levels successful and failed calls are logged at.
+// Redacted bodies are added when the logger is enabled for slog.LevelDebug.
+// Default: slog.LevelInfo and slog.LevelWarn
+func WithLogLevels(success, failure slog.Level) Option {
+	return func(w *WayForPay) {
+		w.logLevel = success
+		w.errorLogLevel = failure
+	}
+}
+
+// WithRedactionPolicy sets the fields hidden from logged bodies. Default: DefaultRedactionPolicy()
+func WithRedactionPolicy(policy RedactionPolicy) Option {
+	return func(w *WayForPay) {
+		w.redaction = policy
+	}
+}
+
+// WithObserver reports every call to observer, see MultiObserver to use several.
+// A nil observer is NopObserver. Default: NopObserver
+func WithObserver(observer Observer) Option {
+	return func(w *WayForPay) {
+		if observer == nil {
+			observer = NopObserver{}
+		}
+		w.observer = observer
+	}
+}
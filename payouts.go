@@ -0,0 +1,182 @@
+package wayforpay
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// PayoutRequest credits funds from the merchant account to a card (P2P_CREDIT).
+// The recipient is either a card number or a card token (rec2Token).
+type PayoutRequest struct {
+	TransactionType   string `json:"transactionType"`
+	MerchantAccount   string `json:"merchantAccount"`
+	MerchantSignature string `json:"merchantSignature"`
+	ApiVersion        int    `json:"apiVersion"`
+	OrderReference    string `json:"orderReference"`
+	Money
+	CardBeneficiary    string `json:"cardBeneficiary,omitempty"`
+	Rec2Token          string `json:"rec2Token,omitempty"`
+	RecipientFirstName string `json:"recipientFirstName"`
+	RecipientLastName  string `json:"recipientLastName"`
+	Description        string `json:"description,omitempty"`
+}
+
+// NewPayoutRequest returns a new PayoutRequest.
+func (w *WayForPay) NewPayoutRequest() *PayoutRequest {
+	return &PayoutRequest{
+		TransactionType: "P2P_CREDIT",
+		MerchantAccount: w.merchantLogin,
+		ApiVersion:      w.apiVersion,
+	}
+}
+
+func (p *PayoutRequest) SetOrderReference(orderReference string) *PayoutRequest {
+	p.OrderReference = orderReference
+	return p
+}
+
+func (p *PayoutRequest) SetAmount(amount Amount) *PayoutRequest {
+	p.Amount = amount
+	return p
+}
+
+// SetMoney sets the amount and the currency.
+func (p *PayoutRequest) SetMoney(money Money) *PayoutRequest {
+	p.Money = money
+	return p
+}
+
+func (p *PayoutRequest) SetCurrency(currency string) *PayoutRequest {
+	p.Currency = currency
+	return p
+}
+
+// SetCard sets the number of the card the funds are credited to.
+func (p *PayoutRequest) SetCard(cardBeneficiary string) *PayoutRequest {
+	p.CardBeneficiary = cardBeneficiary
+	return p
+}
+
+// SetCardToken sets the token of the card the funds are credited to.
+func (p *PayoutRequest) SetCardToken(rec2Token string) *PayoutRequest {
+	p.Rec2Token = rec2Token
+	return p
+}
+
+// SetRecipient sets the name of the card holder.
+func (p *PayoutRequest) SetRecipient(firstName, lastName string) *PayoutRequest {
+	p.RecipientFirstName = firstName
+	p.RecipientLastName = lastName
+	return p
+}
+
+func (p *PayoutRequest) SetDescription(description string) *PayoutRequest {
+	p.Description = description
+	return p
+}
+
+func (p *PayoutRequest) validate() error {
+	if p.TransactionType == "" {
+		return ErrTransactionTypeRequired
+	}
+	if p.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if p.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	if err := p.Money.check(); err != nil {
+		return err
+	}
+	if p.CardBeneficiary == "" && p.Rec2Token == "" {
+		return ErrPayoutCardRequired
+	}
+	if p.RecipientFirstName == "" || p.RecipientLastName == "" {
+		return ErrRecipientNameRequired
+	}
+	return nil
+}
+
+func (p *PayoutRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (p *PayoutRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (p *PayoutRequest) transaction() (transactionType, orderReference string) {
+	return p.TransactionType, p.OrderReference
+}
+
+func (p *PayoutRequest) signatureFields() []string {
+	return []string{
+		p.MerchantAccount,
+		p.OrderReference,
+		p.Amount.String(),
+		p.Currency,
+		p.CardBeneficiary,
+		p.Rec2Token,
+	}
+}
+
+func (p *PayoutRequest) setSignature(signature string) {
+	p.MerchantSignature = signature
+}
+
+// PayoutToCard credits the card of the recipient.
+func (w *WayForPay) PayoutToCard(ctx context.Context, request *PayoutRequest) (*PayoutResponse, error) {
+	return Do[*PayoutRequest, PayoutResponse](ctx, w, request)
+}
+
+type PayoutResponse struct {
+	MerchantAccount   string `json:"merchantAccount"`
+	OrderReference    string `json:"orderReference"`
+	MerchantSignature string `json:"merchantSignature"`
+	Money
+	CardPan           string    `json:"cardPan"`
+	CreatedDate       time.Time `json:"createdDate"`
+	ProcessingDate    time.Time `json:"processingDate"`
+	TransactionStatus string    `json:"transactionStatus"`
+	Reason            string    `json:"reason"`
+	ReasonCode        int       `json:"reasonCode"`
+	Fee               Amount    `json:"fee"`
+}
+
+// UnmarshalJSON decodes the payout result, accepting numbers sent as strings.
+func (p *PayoutResponse) UnmarshalJSON(data []byte) error {
+	type alias PayoutResponse
+	aux := struct {
+		*alias
+		CreatedDate    jsonTime `json:"createdDate"`
+		ProcessingDate jsonTime `json:"processingDate"`
+		ReasonCode     jsonInt  `json:"reasonCode"`
+		Fee            jsonFee  `json:"fee"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.CreatedDate = time.Time(aux.CreatedDate)
+	p.ProcessingDate = time.Time(aux.ProcessingDate)
+	p.ReasonCode = int(aux.ReasonCode)
+	p.Fee = Amount(aux.Fee)
+	return nil
+}
+
+// Approved reports whether the funds were credited to the card.
+func (p *PayoutResponse) Approved() bool {
+	return p.TransactionStatus == "Approved"
+}
+
+func (p *PayoutResponse) Error() error {
+	return reasonError(p.ReasonCode, p.Reason)
+}
+
+func (p *PayoutResponse) GetReasonCode() int {
+	return p.ReasonCode
+}
+
+func (p *PayoutResponse) GetReason() string {
+	return p.Reason
+}
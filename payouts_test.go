@@ -0,0 +1,62 @@
+package wayforpay_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_PayoutToCard(t *testing.T) {
+	var got map[string]any
+	acquirer := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&got))
+			answer := `{"merchantAccount":"test_merch_n1","orderReference":"PAY-1","amount":"250.00","currency":"UAH",` +
+				`"cardPan":"53****0000","transactionStatus":"Approved","reasonCode":1100,"reason":"Ok"}`
+			if got["rec2Token"] == "bad" {
+				answer = `{"orderReference":"PAY-1","transactionStatus":"Declined","reasonCode":1142,"reason":"Refused a credit"}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(answer)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(acquirer, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	resp, err := wfpClient.PayoutToCard(ctx, wfpClient.NewPayoutRequest().
+		SetOrderReference("PAY-1").
+		SetAmount(wfp.MustParseAmount("250.00")).
+		SetCurrency("UAH").
+		SetCard("5375410000000000").
+		SetRecipient("Taras", "Shevchenko"))
+	require.NoError(t, err)
+	require.True(t, resp.Approved())
+	require.Equal(t, wfp.MustParseAmount("250"), resp.Amount)
+	require.Equal(t, "P2P_CREDIT", got["transactionType"])
+	require.Equal(t, sign(merchantSecret, merchantLogin, "PAY-1", "250", "UAH", "5375410000000000", ""), got["merchantSignature"])
+
+	_, err = wfpClient.PayoutToCard(ctx, wfpClient.NewPayoutRequest().
+		SetOrderReference("PAY-1").
+		SetAmount(wfp.MustParseAmount("250.00")).
+		SetCurrency("UAH").
+		SetCardToken("bad").
+		SetRecipient("Taras", "Shevchenko"))
+	require.ErrorIs(t, err, wfp.ErrRefusedCredit)
+
+	_, err = wfpClient.PayoutToCard(ctx, wfpClient.NewPayoutRequest().
+		SetOrderReference("PAY-1").
+		SetAmount(wfp.MustParseAmount("250.00")).
+		SetCurrency("UAH").
+		SetRecipient("Taras", "Shevchenko"))
+	require.ErrorIs(t, err, wfp.ErrPayoutCardRequired)
+}
@@ -0,0 +1,420 @@
+package wayforpay
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PurchaseRequest describes a payment on the WayForPay hosted payment page.
+type PurchaseRequest struct {
+	MerchantAccount         string
+	MerchantAuthType        SignatureMode
+	MerchantDomainName      string
+	MerchantTransactionType string
+	MerchantSignature       string
+	ApiVersion              string
+	Language                string
+	ReturnUrl               string
+	ServiceUrl              string
+	OrderReference          string
+	OrderDate               int64
+	Money
+	AlternativeAmount    Amount
+	AlternativeCurrency  string
+	OrderTimeout         Timeout
+	HoldTimeout          Timeout
+	OrderLifetime        Timeout
+	ProductName          []string
+	ProductPrice         []Amount
+	ProductCount         []int
+	Cart                 *Cart
+	PaymentSystems       string
+	DefaultPaymentSystem string
+	ClientFirstName      string
+	ClientLastName       string
+	ClientEmail          string
+	ClientPhone          string
+	RegularMode          RegularMode
+	RegularAmount        Amount
+	RegularCount         int
+	DateNext             time.Time
+	DateEnd              time.Time
+	RegularBehavior      string
+}
+
+// NewPurchaseRequest returns a new PurchaseRequest.
+func (w *WayForPay) NewPurchaseRequest() *PurchaseRequest {
+	return &PurchaseRequest{
+		MerchantAccount:  w.merchantLogin,
+		MerchantAuthType: SignatureModeSimple,
+		ApiVersion:       strconv.Itoa(w.apiVersion),
+		Language:         w.language,
+	}
+}
+
+// NewPurchaseRequestFromInvoice returns a PurchaseRequest with the order, product
+// and client fields of an invoice, so the same order can be paid on the hosted page.
+func (w *WayForPay) NewPurchaseRequestFromInvoice(invoice *CreateInvoiceRequest) *PurchaseRequest {
+	p := w.NewPurchaseRequest()
+	p.MerchantDomainName = invoice.MerchantDomainName
+	p.MerchantTransactionType = invoice.MerchantTransactionType
+	p.ServiceUrl = invoice.ServiceUrl
+	p.OrderReference = invoice.OrderReference
+	p.OrderDate = invoice.OrderDate
+	p.Money = invoice.Money
+	p.AlternativeAmount = invoice.AlternativeAmount
+	p.AlternativeCurrency = invoice.AlternativeCurrency
+	p.OrderTimeout = invoice.OrderTimeout
+	p.HoldTimeout = invoice.HoldTimeout
+	p.OrderLifetime = invoice.OrderLifetime
+	p.ProductName = slices.Clone(invoice.ProductName)
+	p.ProductPrice = slices.Clone(invoice.ProductPrice)
+	p.ProductCount = slices.Clone(invoice.ProductCount)
+	p.Cart = invoice.Cart
+	p.PaymentSystems = invoice.PaymentSystems
+	p.ClientFirstName = invoice.ClientFirstName
+	p.ClientLastName = invoice.ClientLastName
+	p.ClientEmail = invoice.ClientEmail
+	p.ClientPhone = invoice.ClientPhone
+	return p
+}
+
+// SetMerchantDomainName sets the merchant domain name.
+func (p *PurchaseRequest) SetMerchantDomainName(merchantDomainName string) *PurchaseRequest {
+	p.MerchantDomainName = merchantDomainName
+	return p
+}
+
+// SetMerchantAuthType sets the merchant auth type.
+func (p *PurchaseRequest) SetMerchantAuthType(merchantAuthType SignatureMode) *PurchaseRequest {
+	p.MerchantAuthType = merchantAuthType
+	return p
+}
+
+// SetMerchantTransactionType sets the merchant transaction type.
+// Possible values: AUTO, AUTH, SALE
+func (p *PurchaseRequest) SetMerchantTransactionType(merchantTransactionType string) *PurchaseRequest {
+	p.MerchantTransactionType = merchantTransactionType
+	return p
+}
+
+// SetLanguage sets the payment page language.
+// Possible values: RU, UA, EN
+func (p *PurchaseRequest) SetLanguage(language string) *PurchaseRequest {
+	p.Language = language
+	return p
+}
+
+// SetReturnUrl sets the url the customer is returned to after the payment.
+func (p *PurchaseRequest) SetReturnUrl(returnUrl string) *PurchaseRequest {
+	p.ReturnUrl = returnUrl
+	return p
+}
+
+// SetServiceUrl sets the url WayForPay posts the payment result to.
+func (p *PurchaseRequest) SetServiceUrl(serviceUrl string) *PurchaseRequest {
+	p.ServiceUrl = serviceUrl
+	return p
+}
+
+func (p *PurchaseRequest) SetOrderReference(orderReference string) *PurchaseRequest {
+	p.OrderReference = orderReference
+	return p
+}
+
+func (p *PurchaseRequest) SetOrderDate(orderDate time.Time) *PurchaseRequest {
+	p.OrderDate = orderDate.Unix()
+	return p
+}
+
+func (p *PurchaseRequest) SetAmount(amount Amount) *PurchaseRequest {
+	p.Amount = amount
+	return p
+}
+
+func (p *PurchaseRequest) SetCurrency(currency string) *PurchaseRequest {
+	p.Currency = currency
+	return p
+}
+
+// SetMoney sets the amount and the currency.
+func (p *PurchaseRequest) SetMoney(money Money) *PurchaseRequest {
+	p.Money = money
+	return p
+}
+
+func (p *PurchaseRequest) SetAlternativeAmount(alternativeAmount Amount) *PurchaseRequest {
+	p.AlternativeAmount = alternativeAmount
+	return p
+}
+
+func (p *PurchaseRequest) SetAlternativeCurrency(alternativeCurrency string) *PurchaseRequest {
+	p.AlternativeCurrency = alternativeCurrency
+	return p
+}
+
+// SetOrderTimeout sets the time the customer has to pay once the payment is started.
+func (p *PurchaseRequest) SetOrderTimeout(orderTimeout time.Duration) *PurchaseRequest {
+	p.OrderTimeout = NewTimeout(orderTimeout)
+	return p
+}
+
+// SetOrderLifetime sets how long the order can be paid.
+func (p *PurchaseRequest) SetOrderLifetime(orderLifetime time.Duration) *PurchaseRequest {
+	p.OrderLifetime = NewTimeout(orderLifetime)
+	return p
+}
+
+// SetHold makes the payment two-step: funds are held for holdTimeout until SETTLE.
+func (p *PurchaseRequest) SetHold(holdTimeout time.Duration) *PurchaseRequest {
+	p.MerchantTransactionType = "AUTH"
+	p.HoldTimeout = NewTimeout(holdTimeout)
+	return p
+}
+
+// AddProduct adds a product line of count units at price each. Unless a cart
+// is set, the lines must add up to the amount.
+func (p *PurchaseRequest) AddProduct(name string, price Amount, count int) *PurchaseRequest {
+	p.ProductName = append(p.ProductName, name)
+	p.ProductPrice = append(p.ProductPrice, price)
+	p.ProductCount = append(p.ProductCount, count)
+	return p
+}
+
+// SetCart sets the product lines and the amount from cart. The cart replaces the
+// lines added with AddProduct and must still match the amount when sent.
+func (p *PurchaseRequest) SetCart(cart *Cart) *PurchaseRequest {
+	p.Cart = cart
+	p.Amount = cart.Total()
+	return p
+}
+
+func (p *PurchaseRequest) SetPaymentSystems(paymentSystems ...string) *PurchaseRequest {
+	p.PaymentSystems = strings.Join(paymentSystems, ";")
+	return p
+}
+
+func (p *PurchaseRequest) SetDefaultPaymentSystem(defaultPaymentSystem string) *PurchaseRequest {
+	p.DefaultPaymentSystem = defaultPaymentSystem
+	return p
+}
+
+func (p *PurchaseRequest) SetClientFirstName(clientFirstName string) *PurchaseRequest {
+	p.ClientFirstName = clientFirstName
+	return p
+}
+
+func (p *PurchaseRequest) SetClientLastName(clientLastName string) *PurchaseRequest {
+	p.ClientLastName = clientLastName
+	return p
+}
+
+func (p *PurchaseRequest) SetClientEmail(clientEmail string) *PurchaseRequest {
+	p.ClientEmail = clientEmail
+	return p
+}
+
+func (p *PurchaseRequest) SetClientPhone(clientPhone string) *PurchaseRequest {
+	p.ClientPhone = clientPhone
+	return p
+}
+
+// SetRecurring makes WayForPay repeat the payment on the given schedule.
+func (p *PurchaseRequest) SetRecurring(mode RegularMode, amount Amount, dateNext, dateEnd time.Time) *PurchaseRequest {
+	p.RegularMode = mode
+	p.RegularAmount = amount
+	p.DateNext = dateNext
+	p.DateEnd = dateEnd
+	p.RegularBehavior = "preset"
+	return p
+}
+
+// SetRegularCount limits the number of recurring payments.
+func (p *PurchaseRequest) SetRegularCount(regularCount int) *PurchaseRequest {
+	p.RegularCount = regularCount
+	return p
+}
+
+func (p *PurchaseRequest) sign(secret string) {
+	if p.Cart != nil {
+		p.ProductName, p.ProductPrice, p.ProductCount = p.Cart.products()
+	}
+	data := []string{
+		p.MerchantAccount,
+		p.MerchantDomainName,
+		p.OrderReference,
+		strconv.FormatInt(p.OrderDate, 10),
+		p.Amount.String(),
+		p.Currency,
+	}
+
+	data = append(data, productFields(p.ProductName, p.ProductPrice, p.ProductCount)...)
+	p.MerchantSignature = signature(secret, data)
+}
+
+func (p *PurchaseRequest) validate() error {
+	if p.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if p.MerchantDomainName == "" {
+		return ErrMerchantDomainNameRequired
+	}
+	if p.MerchantSignature == "" {
+		return ErrMerchantSignatureRequired
+	}
+	if p.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	if p.OrderDate == 0 {
+		return ErrOrderDateRequired
+	}
+	if err := p.Money.check(); err != nil {
+		return err
+	}
+	if err := validateTimeouts(p.OrderTimeout, p.HoldTimeout, p.OrderLifetime); err != nil {
+		return err
+	}
+	if err := checkProducts(p.Amount, p.Cart, p.ProductName, p.ProductPrice, p.ProductCount); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *PurchaseRequest) fields() url.Values {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("merchantAccount", p.MerchantAccount)
+	set("merchantAuthType", string(p.MerchantAuthType))
+	set("merchantDomainName", p.MerchantDomainName)
+	set("merchantTransactionType", p.MerchantTransactionType)
+	set("merchantSignature", p.MerchantSignature)
+	set("apiVersion", p.ApiVersion)
+	set("language", p.Language)
+	set("returnUrl", p.ReturnUrl)
+	set("serviceUrl", p.ServiceUrl)
+	set("orderReference", p.OrderReference)
+	set("orderDate", strconv.FormatInt(p.OrderDate, 10))
+	set("amount", p.Amount.String())
+	set("currency", p.Currency)
+	if !p.AlternativeAmount.IsZero() {
+		set("alternativeAmount", p.AlternativeAmount.String())
+	}
+	set("alternativeCurrency", p.AlternativeCurrency)
+	if p.OrderTimeout > 0 {
+		set("orderTimeout", strconv.FormatInt(int64(p.OrderTimeout), 10))
+	}
+	if p.HoldTimeout > 0 {
+		set("holdTimeout", strconv.FormatInt(int64(p.HoldTimeout), 10))
+	}
+	if p.OrderLifetime > 0 {
+		set("orderLifetime", strconv.FormatInt(int64(p.OrderLifetime), 10))
+	}
+	v["productName[]"] = p.ProductName
+	for _, price := range p.ProductPrice {
+		v.Add("productPrice[]", price.String())
+	}
+	for _, count := range p.ProductCount {
+		v.Add("productCount[]", strconv.Itoa(count))
+	}
+	set("paymentSystems", p.PaymentSystems)
+	set("defaultPaymentSystem", p.DefaultPaymentSystem)
+	set("clientFirstName", p.ClientFirstName)
+	set("clientLastName", p.ClientLastName)
+	set("clientEmail", p.ClientEmail)
+	set("clientPhone", p.ClientPhone)
+	if p.RegularMode != "" {
+		set("regularMode", string(p.RegularMode))
+		if !p.RegularAmount.IsZero() {
+			set("regularAmount", p.RegularAmount.String())
+		}
+		set("regularBehavior", p.RegularBehavior)
+		set("regularOn", "1")
+		if p.RegularCount > 0 {
+			set("regularCount", strconv.Itoa(p.RegularCount))
+		}
+		if !p.DateNext.IsZero() {
+			set("dateNext", p.DateNext.Format("02.01.2006"))
+		}
+		if !p.DateEnd.IsZero() {
+			set("dateEnd", p.DateEnd.Format("02.01.2006"))
+		}
+	}
+	return v
+}
+
+// Form is an HTML form the customer's browser posts to WayForPay or to the card issuer.
+type Form struct {
+	// Action is the url the form is posted to.
+	Action string
+	// Fields are the form fields.
+	Fields url.Values
+}
+
+// PurchaseForm signs and validates the request and returns the form for the hosted payment page.
+func (w *WayForPay) PurchaseForm(request *PurchaseRequest) (*Form, error) {
+	request.sign(w.merchantSecret)
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	return &Form{
+		Action: w.purchaseURL,
+		Fields: request.fields(),
+	}, nil
+}
+
+// URL returns the form url with the fields added to its query string,
+// suitable for an HTTP redirect.
+func (f *Form) URL() (string, error) {
+	u, err := url.Parse(f.Action)
+	if err != nil {
+		return "", err
+	}
+	if fields := f.Fields.Encode(); fields != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += fields
+	}
+	return u.String(), nil
+}
+
+var purchaseFormTemplate = template.Must(template.New("purchase").Parse(
+	`<form method="post" action="{{.Action}}" accept-charset="utf-8" id="wayforpay-form">` +
+		`{{range .Fields}}<input type="hidden" name="{{.Name}}" value="{{.Value}}">{{end}}` +
+		`</form><script>document.getElementById("wayforpay-form").submit();</script>`))
+
+// HTML renders a form that submits itself as soon as it is loaded.
+func (f *Form) HTML() (template.HTML, error) {
+	type field struct{ Name, Value string }
+	keys := make([]string, 0, len(f.Fields))
+	for key := range f.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]field, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range f.Fields[key] {
+			fields = append(fields, field{Name: key, Value: value})
+		}
+	}
+
+	var buf bytes.Buffer
+	err := purchaseFormTemplate.Execute(&buf, struct {
+		Action string
+		Fields []field
+	}{Action: f.Action, Fields: fields})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
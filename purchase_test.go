@@ -0,0 +1,85 @@
+package wayforpay_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_PurchaseForm(t *testing.T) {
+	wfpClient, err := wfp.NewClient(nil, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+
+	orderDate := time.Unix(1415379863, 0)
+	request := wfpClient.NewPurchaseRequest().
+		SetMerchantDomainName("www.market.ua").
+		SetOrderReference("DH783023").
+		SetOrderDate(orderDate).
+		SetAmount(wfp.MustParseAmount("1547.36")).
+		SetCurrency("UAH").
+		SetReturnUrl("https://www.market.ua/return").
+		SetServiceUrl("https://www.market.ua/callback").
+		SetHold(time.Hour).
+		SetRecurring(wfp.RegularModeMonthly, wfp.MustParseAmount("1547.36"), time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), time.Time{}).
+		AddProduct("Процессор Intel Core i5-4670 3.4GHz", wfp.MustParseAmount("1000"), 1).
+		AddProduct("Память Kingston DDR3-1600 4096MB PC3-12800", wfp.MustParseAmount("547.36"), 1)
+
+	form, err := wfpClient.PurchaseForm(request)
+	require.NoError(t, err)
+	require.Equal(t, wfp.DefaultPurchaseURL, form.Action)
+
+	wantSignature := sign(merchantSecret, merchantLogin, "www.market.ua", "DH783023", "1415379863", "1547.36", "UAH",
+		"Процессор Intel Core i5-4670 3.4GHz", "Память Kingston DDR3-1600 4096MB PC3-12800", "1", "1", "1000", "547.36")
+	require.Equal(t, wantSignature, form.Fields.Get("merchantSignature"))
+	require.Equal(t, "AUTH", form.Fields.Get("merchantTransactionType"))
+	require.Equal(t, "3600", form.Fields.Get("holdTimeout"))
+	require.Equal(t, "01.02.2024", form.Fields.Get("dateNext"))
+	require.Equal(t, []string{"1000", "547.36"}, form.Fields["productPrice[]"])
+
+	rawURL, err := form.URL()
+	require.NoError(t, err)
+	redirect, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	require.Equal(t, form.Fields, redirect.Query())
+
+	html, err := form.HTML()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(html), `<form method="post" action="https://secure.wayforpay.com/pay"`))
+	require.Contains(t, string(html), `name="merchantSignature" value="`+wantSignature+`"`)
+	require.Contains(t, string(html), `.submit()`)
+
+	_, err = wfpClient.PurchaseForm(wfpClient.NewPurchaseRequest())
+	require.ErrorIs(t, err, wfp.ErrMerchantDomainNameRequired)
+}
+
+func TestForm_URL(t *testing.T) {
+	fields := url.Values{"orderReference": {"DH 783023"}, "productPrice[]": {"1000", "547.36"}}
+	cases := []struct {
+		name    string
+		action  string
+		fields  url.Values
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", action: "https://secure.wayforpay.com/pay", fields: fields, want: "https://secure.wayforpay.com/pay?orderReference=DH+783023&productPrice%5B%5D=1000&productPrice%5B%5D=547.36"},
+		{name: "action with query", action: "https://secure.wayforpay.com/pay?behavior=offline", fields: fields, want: "https://secure.wayforpay.com/pay?behavior=offline&orderReference=DH+783023&productPrice%5B%5D=1000&productPrice%5B%5D=547.36"},
+		{name: "action with fragment", action: "https://secure.wayforpay.com/pay#form", fields: fields, want: "https://secure.wayforpay.com/pay?orderReference=DH+783023&productPrice%5B%5D=1000&productPrice%5B%5D=547.36#form"},
+		{name: "no fields", action: "https://secure.wayforpay.com/pay", want: "https://secure.wayforpay.com/pay"},
+		{name: "invalid action", action: "https://secure.wayforpay.com/%zz", fields: fields, wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&wfp.Form{Action: tt.action, Fields: tt.fields}).URL()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package wayforpay
+
+import "errors"
+
+// ReasonCodeOk is the reason code of a successful operation.
+const ReasonCodeOk = 1100
+
+// ReasonClass tells what can be done about a failed operation.
+type ReasonClass int
+
+const (
+	// ReasonClassFinal means the operation must not be repeated as is.
+	ReasonClassFinal ReasonClass = iota
+	// ReasonClassRetryable means the same operation may succeed later.
+	ReasonClassRetryable
+	// ReasonClassCustomerFixable means the customer can fix the problem,
+	// e.g. by using another card or completing 3-D Secure.
+	ReasonClassCustomerFixable
+)
+
+func (c ReasonClass) String() string {
+	switch c {
+	case ReasonClassRetryable:
+		return "retryable"
+	case ReasonClassCustomerFixable:
+		return "customer-fixable"
+	default:
+		return "final"
+	}
+}
+
+// Sentinel errors for the documented WayForPay reason codes.
+// An *APIError matches the sentinel of its reason code with errors.Is.
+var (
+	ErrDeclinedToCardIssuer                 = errors.New("declined to card issuer")
+	ErrBadCVV2                              = errors.New("bad CVV2")
+	ErrExpiredCard                          = errors.New("expired card")
+	ErrInsufficientFunds                    = errors.New("insufficient funds")
+	ErrInvalidCard                          = errors.New("invalid card")
+	ErrExceedWithdrawalFrequency            = errors.New("exceed withdrawal frequency")
+	ErrThreeDSFail                          = errors.New("3-D Secure failed")
+	ErrFormatError                          = errors.New("format error")
+	ErrInvalidCurrency                      = errors.New("invalid currency")
+	ErrDuplicateOrderID                     = errors.New("duplicate order id")
+	ErrInvalidSignature                     = errors.New("invalid signature")
+	ErrFraud                                = errors.New("fraud")
+	ErrParameterMissing                     = errors.New("parameter is missing")
+	ErrTokenNotFound                        = errors.New("token not found")
+	ErrAPINotAllowed                        = errors.New("api not allowed")
+	ErrMerchantRestriction                  = errors.New("merchant restriction")
+	ErrAuthenticationUnavailable            = errors.New("authentication unavailable")
+	ErrAccountNotFound                      = errors.New("account not found")
+	ErrGateDeclined                         = errors.New("gate declined")
+	ErrRefundNotAllowed                     = errors.New("refund not allowed")
+	ErrCardholderSessionExpired             = errors.New("cardholder session expired")
+	ErrCardholderCanceled                   = errors.New("cardholder canceled the request")
+	ErrIllegalOrderState                    = errors.New("illegal order state")
+	ErrOrderNotFound                        = errors.New("order not found")
+	ErrRefundLimitExceeded                  = errors.New("refund limit exceeded")
+	ErrScriptError                          = errors.New("script error")
+	ErrInvalidAmount                        = errors.New("invalid amount")
+	ErrTransactionInProcessing              = errors.New("transaction in processing")
+	ErrTransactionDelayed                   = errors.New("transaction is delayed")
+	ErrInvalidCommission                    = errors.New("invalid commission")
+	ErrTransactionPending                   = errors.New("transaction is pending")
+	ErrCardLimitsFailed                     = errors.New("card limits failed")
+	ErrMerchantBalanceTooSmall              = errors.New("merchant balance is very small")
+	ErrInvalidConfirmationAmount            = errors.New("invalid confirmation amount")
+	ErrRefundInProcessing                   = errors.New("refund in processing")
+	ErrExternalDeclineWhileCredit           = errors.New("external decline while credit")
+	ErrExceedWithdrawalFrequencyWhileCredit = errors.New("exceed withdrawal frequency while credit")
+	ErrPartialVoidNotSupported              = errors.New("partial void is not supported")
+	ErrRefusedCredit                        = errors.New("refused a credit")
+	ErrInvalidPhoneNumber                   = errors.New("invalid phone number")
+	ErrAwaitingDelivery                     = errors.New("transaction is awaiting delivery")
+	ErrWaitThreeDS                          = errors.New("waiting for 3-D Secure data")
+)
+
+type reasonInfo struct {
+	err   error
+	class ReasonClass
+}
+
+var reasonCodes = map[int]reasonInfo{
+	1101: {ErrDeclinedToCardIssuer, ReasonClassCustomerFixable},
+	1102: {ErrBadCVV2, ReasonClassCustomerFixable},
+	1103: {ErrExpiredCard, ReasonClassCustomerFixable},
+	1104: {ErrInsufficientFunds, ReasonClassCustomerFixable},
+	1105: {ErrInvalidCard, ReasonClassCustomerFixable},
+	1106: {ErrExceedWithdrawalFrequency, ReasonClassCustomerFixable},
+	1108: {ErrThreeDSFail, ReasonClassCustomerFixable},
+	1109: {ErrFormatError, ReasonClassFinal},
+	1110: {ErrInvalidCurrency, ReasonClassFinal},
+	1112: {ErrDuplicateOrderID, ReasonClassFinal},
+	1113: {ErrInvalidSignature, ReasonClassFinal},
+	1114: {ErrFraud, ReasonClassFinal},
+	1115: {ErrParameterMissing, ReasonClassFinal},
+	1116: {ErrTokenNotFound, ReasonClassFinal},
+	1117: {ErrAPINotAllowed, ReasonClassFinal},
+	1118: {ErrMerchantRestriction, ReasonClassFinal},
+	1120: {ErrAuthenticationUnavailable, ReasonClassRetryable},
+	1121: {ErrAccountNotFound, ReasonClassFinal},
+	1122: {ErrGateDeclined, ReasonClassRetryable},
+	1123: {ErrRefundNotAllowed, ReasonClassFinal},
+	1124: {ErrCardholderSessionExpired, ReasonClassCustomerFixable},
+	1125: {ErrCardholderCanceled, ReasonClassCustomerFixable},
+	1126: {ErrIllegalOrderState, ReasonClassFinal},
+	1127: {ErrOrderNotFound, ReasonClassFinal},
+	1128: {ErrRefundLimitExceeded, ReasonClassFinal},
+	1129: {ErrScriptError, ReasonClassRetryable},
+	1130: {ErrInvalidAmount, ReasonClassFinal},
+	1131: {ErrTransactionInProcessing, ReasonClassRetryable},
+	1132: {ErrTransactionDelayed, ReasonClassRetryable},
+	1133: {ErrInvalidCommission, ReasonClassFinal},
+	1134: {ErrTransactionPending, ReasonClassRetryable},
+	1135: {ErrCardLimitsFailed, ReasonClassCustomerFixable},
+	1136: {ErrMerchantBalanceTooSmall, ReasonClassFinal},
+	1137: {ErrInvalidConfirmationAmount, ReasonClassFinal},
+	1138: {ErrRefundInProcessing, ReasonClassRetryable},
+	1139: {ErrExternalDeclineWhileCredit, ReasonClassFinal},
+	1140: {ErrExceedWithdrawalFrequencyWhileCredit, ReasonClassCustomerFixable},
+	1141: {ErrPartialVoidNotSupported, ReasonClassFinal},
+	1142: {ErrRefusedCredit, ReasonClassFinal},
+	1143: {ErrInvalidPhoneNumber, ReasonClassCustomerFixable},
+	1144: {ErrAwaitingDelivery, ReasonClassRetryable},
+	5100: {ErrWaitThreeDS, ReasonClassCustomerFixable},
+}
+
+// ReasonCodeError returns the sentinel error of a reason code, or nil if the code is unknown or Ok.
+func ReasonCodeError(code int) error {
+	return reasonCodes[code].err
+}
+
+// ClassifyReasonCode tells whether a failed operation may be retried, can be
+// fixed by the customer or is final. Unknown codes are treated as final.
+func ClassifyReasonCode(code int) ReasonClass {
+	return reasonCodes[code].class
+}
+
+// reasonError returns an *APIError for every reason code but ReasonCodeOk.
+func reasonError(code int, reason string) error {
+	if code == ReasonCodeOk {
+		return nil
+	}
+	return &APIError{ReasonCode: code, Reason: reason}
+}
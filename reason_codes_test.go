@@ -0,0 +1,57 @@
+package wayforpay_test
+
+import (
+	"errors"
+	"testing"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		sentinel  error
+		class     wfp.ReasonClass
+		retryable bool
+	}{
+		{
+			name:     "insufficient funds",
+			err:      &wfp.APIError{ReasonCode: 1104, Reason: "Insufficient Funds"},
+			sentinel: wfp.ErrInsufficientFunds,
+			class:    wfp.ReasonClassCustomerFixable,
+		},
+		{
+			name:     "duplicate order",
+			err:      &wfp.APIError{ReasonCode: 1112, Reason: "Duplicate Order ID"},
+			sentinel: wfp.ErrDuplicateOrderID,
+			class:    wfp.ReasonClassFinal,
+		},
+		{
+			name:      "transaction in processing",
+			err:       &wfp.APIError{ReasonCode: 1131, Reason: "Transaction in processing"},
+			sentinel:  wfp.ErrTransactionInProcessing,
+			class:     wfp.ReasonClassRetryable,
+			retryable: true,
+		},
+		{
+			name:  "unknown code",
+			err:   &wfp.APIError{ReasonCode: 9999, Reason: "Unknown"},
+			class: wfp.ReasonClassFinal,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sentinel != nil {
+				require.True(t, errors.Is(tt.err, tt.sentinel))
+			}
+			require.False(t, errors.Is(tt.err, wfp.ErrExpiredCard))
+
+			var apiErr *wfp.APIError
+			require.True(t, errors.As(tt.err, &apiErr))
+			require.Equal(t, tt.class, apiErr.Class())
+			require.Equal(t, tt.retryable, apiErr.Retryable())
+		})
+	}
+}
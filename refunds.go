@@ -1,83 +1,89 @@
 package wayforpay
 
 import (
-	"crypto/hmac"
-	"crypto/md5"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
-	"io"
-	"strconv"
-	"strings"
+	"context"
 )
 
 type RefundRequest struct {
-	TransactionType   string `json:"transactionType"`
-	MerchantAccount   string `json:"merchantAccount"`
-	OrderReference    string `json:"orderReference"`
-	Amount            int    `json:"amount"`
-	Currency          string `json:"currency"`
+	TransactionType string `json:"transactionType"`
+	MerchantAccount string `json:"merchantAccount"`
+	OrderReference  string `json:"orderReference"`
+	Money
 	Comment           string `json:"comment"`
 	MerchantSignature string `json:"merchantSignature"`
 	ApiVersion        int    `json:"apiVersion"`
 }
 
+// CreateRefund refunds the order payment fully or partially.
 func (w *WayForPay) CreateRefund(request *RefundRequest) (*RefundResponse, error) {
+	return w.CreateRefundContext(context.Background(), request)
+}
 
-	respBody := request.body(w.merchantSecret)
-	if err := request.validate(); err != nil {
-		return nil, err
-	}
-	params, err := request.params()
-	if err != nil {
-		return nil, err
-	}
-	var cir RefundResponse
-	if err := w.makeRequest(fmt.Sprintf(APIEndpoint, request.method()), respBody, &cir, params); err != nil {
-		return nil, err
-	}
-	return &cir, nil
+// CreateRefundContext refunds the order payment, aborting the call when ctx is done.
+func (w *WayForPay) CreateRefundContext(ctx context.Context, request *RefundRequest) (*RefundResponse, error) {
+	return Do[*RefundRequest, RefundResponse](ctx, w, request)
 }
 
 func (r *RefundRequest) validate() error {
-	return nil
+	if r.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	return r.Money.check()
 }
 
 func (r *RefundRequest) params() (Params, error) {
 	return Params{}, nil
 }
 
-func (r *RefundRequest) method() string {
-	return ""
+func (r *RefundRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (r *RefundRequest) transaction() (transactionType, orderReference string) {
+	return r.TransactionType, r.OrderReference
+}
+
+// reconcile treats a grown refunded amount, or a hold voided since the first
+// attempt, as the refund made by it and answers with the order status.
+func (r *RefundRequest) reconcile(before, after *CheckStatusResponse, response Responder) (bool, error) {
+	if before == nil || after == nil {
+		return false, nil
+	}
+	voided := before.TransactionStatus != after.TransactionStatus && after.TransactionStatus == "Voided"
+	if after.RefundAmount.Cmp(before.RefundAmount) <= 0 && !voided {
+		return false, nil
+	}
+	if res, ok := response.(*RefundResponse); ok {
+		*res = RefundResponse{
+			OrderReference:    after.OrderReference,
+			TransactionStatus: after.TransactionStatus,
+			ReasonCode:        ReasonCodeOk,
+			Reason:            "Ok",
+			MerchantAccount:   after.MerchantAccount,
+		}
+	}
+	return true, nil
 }
 
 func (w *WayForPay) NewRefundRequest() *RefundRequest {
 	return &RefundRequest{
 		TransactionType: "REFUND",
-		ApiVersion:      1,
+		ApiVersion:      w.apiVersion,
 		MerchantAccount: w.merchantLogin,
 	}
 }
 
-func (r *RefundRequest) body(secret string) io.Reader {
-	data := []string{
+func (r *RefundRequest) signatureFields() []string {
+	return []string{
 		r.MerchantAccount,
 		r.OrderReference,
-		strconv.FormatInt(int64(r.Amount), 10),
+		r.Amount.String(),
 		r.Currency,
 	}
+}
 
-	message := strings.Join(data, ";")
-	h := hmac.New(md5.New, []byte(secret))
-	h.Write([]byte(message))
-	r.MerchantSignature = hex.EncodeToString(h.Sum(nil))
-
-	body, err := json.Marshal(r)
-	if err != nil {
-		return nil
-	}
-
-	return strings.NewReader(string(body))
+func (r *RefundRequest) setSignature(signature string) {
+	r.MerchantSignature = signature
 }
 
 func (r *RefundRequest) SetMerchantAccount(merchantAccount string) *RefundRequest {
@@ -90,11 +96,17 @@ func (r *RefundRequest) SetOrderReference(orderReference string) *RefundRequest
 	return r
 }
 
-func (r *RefundRequest) SetAmount(amount int) *RefundRequest {
+func (r *RefundRequest) SetAmount(amount Amount) *RefundRequest {
 	r.Amount = amount
 	return r
 }
 
+// SetMoney sets the amount and the currency.
+func (r *RefundRequest) SetMoney(money Money) *RefundRequest {
+	r.Money = money
+	return r
+}
+
 func (r *RefundRequest) SetCurrency(currency string) *RefundRequest {
 	r.Currency = currency
 	return r
@@ -124,10 +136,7 @@ type RefundResponse struct {
 }
 
 func (c *RefundResponse) Error() error {
-	if c.ReasonCode != 1100 {
-		return fmt.Errorf("%d: %s", c.ReasonCode, c.Reason)
-	}
-	return nil
+	return reasonError(c.ReasonCode, c.Reason)
 }
 
 func (c *RefundResponse) GetReasonCode() int {
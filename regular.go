@@ -0,0 +1,289 @@
+package wayforpay
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ReasonCodeRegularOk is the reason code of a successful regular payments API call.
+const ReasonCodeRegularOk = 4100
+
+// RegularMode is the schedule of a regular payment.
+type RegularMode string
+
+const (
+	RegularModeDaily      RegularMode = "daily"
+	RegularModeWeekly     RegularMode = "weekly"
+	RegularModeMonthly    RegularMode = "monthly"
+	RegularModeQuarterly  RegularMode = "quarterly"
+	RegularModeHalfYearly RegularMode = "halfyearly"
+	RegularModeYearly     RegularMode = "yearly"
+)
+
+// RegularStatus is the state of a regular payment.
+type RegularStatus string
+
+const (
+	RegularStatusCreated   RegularStatus = "Created"
+	RegularStatusConfirmed RegularStatus = "Confirmed"
+	RegularStatusActive    RegularStatus = "Active"
+	RegularStatusSuspended RegularStatus = "Suspended"
+	RegularStatusRemoved   RegularStatus = "Removed"
+	RegularStatusCompleted RegularStatus = "Completed"
+)
+
+// RegularPayments is a client of the WayForPay regular (recurring) payments API.
+// It shares the credentials and the HTTP transport of the WayForPay client;
+// the merchant password must be set with WithMerchantPassword.
+type RegularPayments struct {
+	w *WayForPay
+}
+
+// RegularPayments returns the regular payments API client.
+func (w *WayForPay) RegularPayments() *RegularPayments {
+	return &RegularPayments{w: w}
+}
+
+type RegularRequest struct {
+	RequestType      string      `json:"requestType"`
+	MerchantAccount  string      `json:"merchantAccount"`
+	MerchantPassword string      `json:"merchantPassword"`
+	OrderReference   string      `json:"orderReference"`
+	RegularMode      RegularMode `json:"regularMode,omitempty"`
+	Money
+	DateBegin string `json:"dateBegin,omitempty"`
+	DateEnd   string `json:"dateEnd,omitempty"`
+	Email     string `json:"email,omitempty"`
+	RecToken  string `json:"recToken,omitempty"`
+}
+
+// MarshalJSON encodes the request, leaving amount out when it is zero, as in
+// STATUS, SUSPEND and REMOVE requests.
+func (q RegularRequest) MarshalJSON() ([]byte, error) {
+	type alias RegularRequest
+	return json.Marshal(struct {
+		alias
+		Amount *Amount `json:"amount,omitempty"`
+	}{alias: alias(q), Amount: q.Amount.orNil()})
+}
+
+func (r *RegularPayments) newRequest(requestType, orderReference string) *RegularRequest {
+	return &RegularRequest{
+		RequestType:      requestType,
+		MerchantAccount:  r.w.merchantLogin,
+		MerchantPassword: r.w.merchantPassword,
+		OrderReference:   orderReference,
+	}
+}
+
+// NewCreateRequest returns a request that creates a regular payment for orderReference.
+func (r *RegularPayments) NewCreateRequest(orderReference string) *RegularRequest {
+	return r.newRequest("CREATE", orderReference)
+}
+
+// NewChangeRequest returns a request that changes the regular payment of orderReference.
+func (r *RegularPayments) NewChangeRequest(orderReference string) *RegularRequest {
+	return r.newRequest("CHANGE", orderReference)
+}
+
+func (q *RegularRequest) SetRegularMode(regularMode RegularMode) *RegularRequest {
+	q.RegularMode = regularMode
+	return q
+}
+
+func (q *RegularRequest) SetAmount(amount Amount) *RegularRequest {
+	q.Amount = amount
+	return q
+}
+
+// SetMoney sets the amount and the currency.
+func (q *RegularRequest) SetMoney(money Money) *RegularRequest {
+	q.Money = money
+	return q
+}
+
+func (q *RegularRequest) SetCurrency(currency string) *RegularRequest {
+	q.Currency = currency
+	return q
+}
+
+// SetDateBegin sets the date of the first payment.
+func (q *RegularRequest) SetDateBegin(dateBegin time.Time) *RegularRequest {
+	q.DateBegin = dateBegin.Format("02.01.2006")
+	return q
+}
+
+// SetDateEnd sets the date after which no payments are made.
+func (q *RegularRequest) SetDateEnd(dateEnd time.Time) *RegularRequest {
+	q.DateEnd = dateEnd.Format("02.01.2006")
+	return q
+}
+
+func (q *RegularRequest) SetEmail(email string) *RegularRequest {
+	q.Email = email
+	return q
+}
+
+// SetRecToken sets the card token the regular payment is charged to.
+func (q *RegularRequest) SetRecToken(recToken string) *RegularRequest {
+	q.RecToken = recToken
+	return q
+}
+
+func (q *RegularRequest) validate() error {
+	if q.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if q.MerchantPassword == "" {
+		return ErrMerchantPasswordRequired
+	}
+	if q.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	if q.RequestType != "CREATE" {
+		return nil
+	}
+	if q.RegularMode == "" {
+		return ErrRegularModeRequired
+	}
+	if err := q.Money.check(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *RegularRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (q *RegularRequest) method() (API, string) {
+	return APIRegular, ""
+}
+
+func (q *RegularRequest) transaction() (transactionType, orderReference string) {
+	return q.RequestType, q.OrderReference
+}
+
+// idempotent reports every request but CREATE as safe to repeat: they read or
+// set the state of an existing schedule.
+func (q *RegularRequest) idempotent() bool {
+	return q.RequestType != "CREATE"
+}
+
+// signatureFields returns nil: the regular payments API authenticates with
+// merchantPassword instead of a signature.
+func (q *RegularRequest) signatureFields() []string {
+	return nil
+}
+
+func (q *RegularRequest) setSignature(string) {}
+
+// Create creates a regular payment.
+func (r *RegularPayments) Create(ctx context.Context, request *RegularRequest) (*RegularResponse, error) {
+	return Do[*RegularRequest, RegularResponse](ctx, r.w, request)
+}
+
+// Change changes the schedule, amount or card of a regular payment.
+func (r *RegularPayments) Change(ctx context.Context, request *RegularRequest) (*RegularResponse, error) {
+	return Do[*RegularRequest, RegularResponse](ctx, r.w, request)
+}
+
+// Status returns the state of the regular payment of orderReference.
+func (r *RegularPayments) Status(ctx context.Context, orderReference string) (*RegularStatusResponse, error) {
+	return Do[*RegularRequest, RegularStatusResponse](ctx, r.w, r.newRequest("STATUS", orderReference))
+}
+
+// Suspend pauses the regular payment of orderReference.
+func (r *RegularPayments) Suspend(ctx context.Context, orderReference string) (*RegularResponse, error) {
+	return Do[*RegularRequest, RegularResponse](ctx, r.w, r.newRequest("SUSPEND", orderReference))
+}
+
+// Resume resumes a suspended regular payment of orderReference.
+func (r *RegularPayments) Resume(ctx context.Context, orderReference string) (*RegularResponse, error) {
+	return Do[*RegularRequest, RegularResponse](ctx, r.w, r.newRequest("RESUME", orderReference))
+}
+
+// Remove removes the regular payment of orderReference for good.
+func (r *RegularPayments) Remove(ctx context.Context, orderReference string) (*RegularResponse, error) {
+	return Do[*RegularRequest, RegularResponse](ctx, r.w, r.newRequest("REMOVE", orderReference))
+}
+
+// regularReasonError returns an *APIError for every reason code but ReasonCodeRegularOk.
+func regularReasonError(code int, reason string) error {
+	if code == ReasonCodeRegularOk {
+		return nil
+	}
+	return &APIError{ReasonCode: code, Reason: reason}
+}
+
+type RegularResponse struct {
+	Reason     string `json:"reason"`
+	ReasonCode int    `json:"reasonCode"`
+}
+
+func (r *RegularResponse) Error() error {
+	return regularReasonError(r.ReasonCode, r.Reason)
+}
+
+func (r *RegularResponse) GetReasonCode() int {
+	return r.ReasonCode
+}
+
+func (r *RegularResponse) GetReason() string {
+	return r.Reason
+}
+
+type RegularStatusResponse struct {
+	OrderReference string        `json:"orderReference"`
+	Mode           RegularMode   `json:"mode"`
+	Status         RegularStatus `json:"status"`
+	Money
+	Email           string    `json:"email"`
+	DateBegin       time.Time `json:"dateBegin"`
+	DateEnd         time.Time `json:"dateEnd"`
+	LastPayedDate   time.Time `json:"lastPayedDate"`
+	LastPayedStatus string    `json:"lastPayedStatus"`
+	NextPaymentDate time.Time `json:"nextPaymentDate"`
+	Reason          string    `json:"reason"`
+	ReasonCode      int       `json:"reasonCode"`
+}
+
+// UnmarshalJSON decodes the STATUS answer, accepting unix timestamps and numbers sent as strings.
+func (r *RegularStatusResponse) UnmarshalJSON(data []byte) error {
+	type alias RegularStatusResponse
+	aux := struct {
+		*alias
+		DateBegin       jsonTime `json:"dateBegin"`
+		DateEnd         jsonTime `json:"dateEnd"`
+		LastPayedDate   jsonTime `json:"lastPayedDate"`
+		NextPaymentDate jsonTime `json:"nextPaymentDate"`
+		ReasonCode      jsonInt  `json:"reasonCode"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.DateBegin = time.Time(aux.DateBegin)
+	r.DateEnd = time.Time(aux.DateEnd)
+	r.LastPayedDate = time.Time(aux.LastPayedDate)
+	r.NextPaymentDate = time.Time(aux.NextPaymentDate)
+	r.ReasonCode = int(aux.ReasonCode)
+	return nil
+}
+
+// Error reports a failed call. A status answer without a reason code describes
+// the regular payment and is not an error.
+func (r *RegularStatusResponse) Error() error {
+	if r.ReasonCode == 0 && r.OrderReference != "" {
+		return nil
+	}
+	return regularReasonError(r.ReasonCode, r.Reason)
+}
+
+func (r *RegularStatusResponse) GetReasonCode() int {
+	return r.ReasonCode
+}
+
+func (r *RegularStatusResponse) GetReason() string {
+	return r.Reason
+}
@@ -0,0 +1,91 @@
+package wayforpay_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegularPayments(t *testing.T) {
+	var got []map[string]any
+	regularAPI := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, wfp.DefaultRegularURL, req.URL.String())
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			got = append(got, body)
+
+			answer := `{"reasonCode":4100,"reason":"Ok"}`
+			switch {
+			case body["requestType"] == "STATUS":
+				answer = `{"orderReference":"SUB-1","mode":"monthly","status":"Active","amount":"99.90","currency":"UAH",` +
+					`"dateBegin":1706745600,"dateEnd":1738368000,"nextPaymentDate":1709251200}`
+			case body["orderReference"] == "missing":
+				answer = `{"reasonCode":4102,"reason":"Not found"}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(answer)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(regularAPI, merchantLogin, merchantSecret, wfp.WithMerchantPassword("password"))
+	require.NoError(t, err)
+	regular := wfpClient.RegularPayments()
+	ctx := context.Background()
+
+	_, err = regular.Create(ctx, regular.NewCreateRequest("SUB-1").
+		SetRegularMode(wfp.RegularModeMonthly).
+		SetAmount(wfp.MustParseAmount("99.90")).
+		SetCurrency("UAH").
+		SetDateBegin(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)).
+		SetRecToken("tok"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"requestType":      "CREATE",
+		"merchantAccount":  merchantLogin,
+		"merchantPassword": "password",
+		"orderReference":   "SUB-1",
+		"regularMode":      "monthly",
+		"amount":           99.9,
+		"currency":         "UAH",
+		"dateBegin":        "01.02.2024",
+		"recToken":         "tok",
+	}, got[0])
+
+	status, err := regular.Status(ctx, "SUB-1")
+	require.NoError(t, err)
+	require.Equal(t, wfp.RegularModeMonthly, status.Mode)
+	require.Equal(t, wfp.RegularStatusActive, status.Status)
+	require.Equal(t, wfp.MustParseAmount("99.9"), status.Amount)
+	require.Equal(t, time.Unix(1709251200, 0), status.NextPaymentDate)
+
+	for _, call := range []func(context.Context, string) (*wfp.RegularResponse, error){regular.Suspend, regular.Resume, regular.Remove} {
+		_, err := call(ctx, "SUB-1")
+		require.NoError(t, err)
+	}
+	require.Equal(t, "SUSPEND", got[2]["requestType"])
+	require.Equal(t, "RESUME", got[3]["requestType"])
+	require.Equal(t, "REMOVE", got[4]["requestType"])
+
+	_, err = regular.Suspend(ctx, "missing")
+	var apiErr *wfp.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 4102, apiErr.ReasonCode)
+
+	_, err = regular.Create(ctx, regular.NewCreateRequest("SUB-2").SetAmount(wfp.MustParseAmount("1")).SetCurrency("UAH"))
+	require.ErrorIs(t, err, wfp.ErrRegularModeRequired)
+
+	noPassword, err := wfp.NewClient(regularAPI, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+	_, err = noPassword.RegularPayments().Status(ctx, "SUB-1")
+	require.ErrorIs(t, err, wfp.ErrMerchantPasswordRequired)
+}
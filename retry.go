@@ -0,0 +1,227 @@
+package wayforpay
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls how a failed call is repeated. The zero value makes a
+// single attempt.
+//
+// Only calls that are safe to repeat are retried: read-only requests are sent
+// again as is, while CREATE_INVOICE, REFUND and SETTLE are first reconciled with
+// CHECK_STATUS on the same orderReference, so a request that was applied but
+// whose answer was lost is never applied twice. When they are declined as in
+// processing (1131, 1134, 1138), or the order is found in processing (e.g.
+// RefundInProcessing), they are not sent again at all; the order is polled
+// with CHECK_STATUS instead. Other requests that move money
+// (charges, payouts, 3-D Secure completion, custom transactions that do not
+// declare themselves idempotent) are always sent once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, the first one included.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every attempt. Values below 1 are treated as 1.
+	Multiplier float64
+	// Jitter is the fraction of the delay, 0..1, that is randomly taken off
+	// every wait so that clients do not retry in lockstep.
+	Jitter float64
+	// RetryableReasonCodes lists the declined reason codes worth repeating.
+	// Nil means every code classified as ReasonClassRetryable.
+	RetryableReasonCodes []int
+}
+
+// DefaultRetryPolicy returns a policy of 3 attempts with exponential backoff
+// starting at 200ms, capped at 5s, with 20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// backoff returns the delay after the given failed attempt, counting from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= max(p.Multiplier, 1)
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, float64(p.MaxBackoff))
+	}
+	if p.Jitter > 0 {
+		d -= rand.Float64() * min(p.Jitter, 1) * d
+	}
+	return time.Duration(d)
+}
+
+// retryable reports whether err is a retryable transport failure or a declined
+// answer with a retryable reason code. A cancelled or expired ctx is never retried.
+func (p RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if p.RetryableReasonCodes == nil {
+			return apiErr.Retryable()
+		}
+		return slices.Contains(p.RetryableReasonCodes, apiErr.ReasonCode)
+	}
+	var transportErr *TransportError
+	return errors.As(err, &transportErr) && transportErr.Retryable()
+}
+
+// idempotent is implemented by requests that may be sent again as is.
+type idempotent interface {
+	idempotent() bool
+}
+
+// reconciler is implemented by requests that must not be applied twice. Before
+// a retry the order is looked up with CHECK_STATUS and reconcile decides
+// whether the failed attempt was applied after all. before and after are the
+// order states ahead of the first attempt and ahead of the retry, nil when the
+// order did not exist. When applied, reconcile fills response if it can and
+// returns the error the call should end with.
+type reconciler interface {
+	reconcile(before, after *CheckStatusResponse, response Responder) (applied bool, err error)
+}
+
+// sendWithRetry sends body according to the retry policy of w and counts the
+// attempts in call.
+//
+// A reconciled request declined as in processing, or whose order has moved
+// since the first attempt without being applied yet, may still be applied by
+// WayForPay, so it is never sent again: the order is polled with CHECK_STATUS
+// until reconcile finds it applied or the attempts run out.
+func (w *WayForPay) sendWithRetry(ctx context.Context, call *Call, endpoint string, body []byte, response Responder, params Params) error {
+	request := call.payment
+	policy := w.retry
+	attempts := max(policy.MaxAttempts, 1)
+
+	rec, reconciled := request.(reconciler)
+	var before *CheckStatusResponse
+	switch {
+	case attempts == 1:
+	case reconciled:
+		var err error
+		if before, err = w.orderState(ctx, call); err != nil {
+			// Without the prior state a lost answer cannot be told apart from
+			// a request that never arrived, so do not risk a second attempt.
+			attempts = 1
+		}
+	default:
+		if r, ok := request.(idempotent); !ok || !r.idempotent() {
+			attempts = 1
+		}
+	}
+
+	send := func() error {
+		call.attempts++
+		return w.send(ctx, call, endpoint, body, response, params)
+	}
+	err := send()
+	pending := false
+	for attempt := 1; err != nil && attempt < attempts && policy.retryable(ctx, err); attempt++ {
+		if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+			return err
+		}
+		if !reconciled {
+			err = send()
+			continue
+		}
+		after, stateErr := w.orderState(ctx, call)
+		if stateErr != nil {
+			return err
+		}
+		if applied, recErr := rec.reconcile(before, after, response); applied {
+			return recErr
+		}
+		pending = pending || inProcessing(err) || orderPending(before, after)
+		if !pending {
+			err = send()
+		}
+	}
+	return err
+}
+
+// inProcessing reports whether err declines a request that WayForPay is still
+// processing and may yet apply.
+func inProcessing(err error) bool {
+	return errors.Is(err, ErrTransactionInProcessing) ||
+		errors.Is(err, ErrTransactionPending) ||
+		errors.Is(err, ErrRefundInProcessing)
+}
+
+// processingStatuses are the order statuses of a request WayForPay accepted
+// but has not finished.
+var processingStatuses = []string{"InProcessing", "Pending", "RefundInProcessing"}
+
+// orderPending reports whether the order moved since the first attempt or is
+// being processed, i.e. whether that attempt may have arrived and still be
+// applied.
+func orderPending(before, after *CheckStatusResponse) bool {
+	if after == nil {
+		return false
+	}
+	if before != nil && before.TransactionStatus != after.TransactionStatus {
+		return true
+	}
+	return slices.Contains(processingStatuses, after.TransactionStatus)
+}
+
+// orderState returns the CHECK_STATUS answer for the order of call, or nil
+// when WayForPay does not know the order. The lookup is part of call: it skips
+// the interceptors and the observer, carries the headers of call and is sent
+// once, within the attempts of call.
+func (w *WayForPay) orderState(ctx context.Context, call *Call) (*CheckStatusResponse, error) {
+	_, orderReference := call.payment.transaction()
+	request := w.NewCheckStatus(orderReference)
+	body, params, err := w.encode(request)
+	if err != nil {
+		return nil, err
+	}
+	lookup := &Call{
+		TransactionType: request.TransactionType,
+		OrderReference:  orderReference,
+		Request:         request,
+		Header:          call.Header,
+		payment:         request,
+	}
+	var status CheckStatusResponse
+	err = w.send(ctx, lookup, w.endpoint(request), body, &status, params)
+	if errors.Is(err, ErrOrderNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+package wayforpay_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_Retry(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(t *testing.T, policy wfp.RetryPolicy) (*wfp.WayForPay, *wayforpaytest.Server) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		t.Cleanup(srv.Close)
+		wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret, wfp.WithRetryPolicy(policy))
+		require.NoError(t, err)
+		return wfpClient, srv
+	}
+	policy := wfp.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+	newInvoice := func(wfpClient *wfp.WayForPay, orderReference string) *wfp.CreateInvoiceRequest {
+		return wfpClient.NewCreateInvoiceRequest().
+			SetMerchantDomainName("test.com").
+			SetOrderDate(time.Now()).
+			SetMoney(wfp.NewMoney(wfp.MustParseAmount("100"), "UAH")).
+			SetOrderReference(orderReference).
+			AddProduct("test", wfp.MustParseAmount("100"), 1)
+	}
+	paidOrder := func(t *testing.T, wfpClient *wfp.WayForPay, srv *wayforpaytest.Server) string {
+		orderReference := uuid.NewString()
+		_, err := wfpClient.CreateInvoiceContext(ctx, newInvoice(wfpClient, orderReference))
+		require.NoError(t, err)
+		require.NoError(t, srv.Approve(orderReference))
+		return orderReference
+	}
+	refund := func(wfpClient *wfp.WayForPay, orderReference string) (*wfp.RefundResponse, error) {
+		return wfpClient.CreateRefundContext(ctx, wfpClient.NewRefundRequest().
+			SetOrderReference(orderReference).
+			SetMoney(wfp.NewMoney(wfp.MustParseAmount("40"), "UAH")))
+	}
+
+	t.Run("lost refund answer is reconciled, not refunded twice", func(t *testing.T) {
+		wfpClient, srv := newClient(t, policy)
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		srv.DropNextResponse("REFUND")
+		resp, err := refund(wfpClient, orderReference)
+		require.NoError(t, err)
+		require.Equal(t, orderReference, resp.OrderReference)
+		require.Equal(t, wfp.ReasonCodeOk, resp.ReasonCode)
+		require.Equal(t, 1, srv.Calls("REFUND"))
+
+		order, _ := srv.Order(orderReference)
+		require.Equal(t, 40.0, order.RefundedAmount)
+	})
+
+	t.Run("refund in processing is polled, not sent again", func(t *testing.T) {
+		wfpClient, srv := newClient(t, policy)
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		calls := srv.Calls("CHECK_STATUS")
+		srv.FailNext("REFUND", 1138, "Refund in processing")
+		_, err := refund(wfpClient, orderReference)
+		require.ErrorIs(t, err, wfp.ErrRefundInProcessing)
+		require.Equal(t, 1, srv.Calls("REFUND"))
+		// One snapshot before the refund and one poll per remaining attempt.
+		require.Equal(t, calls+3, srv.Calls("CHECK_STATUS"))
+
+		order, _ := srv.Order(orderReference)
+		require.Zero(t, order.RefundedAmount)
+	})
+
+	t.Run("lost answer of a refund still in processing is polled, not sent again", func(t *testing.T) {
+		wfpClient, srv := newClient(t, policy)
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		calls := srv.Calls("CHECK_STATUS")
+		srv.HoldNextRefund()
+		srv.DropNextResponse("REFUND")
+		_, err := refund(wfpClient, orderReference)
+		var transportErr *wfp.TransportError
+		require.ErrorAs(t, err, &transportErr)
+		require.Equal(t, 1, srv.Calls("REFUND"))
+		require.Equal(t, calls+3, srv.Calls("CHECK_STATUS"))
+
+		require.NoError(t, srv.FinishRefund(orderReference))
+		order, _ := srv.Order(orderReference)
+		require.Equal(t, 40.0, order.RefundedAmount)
+	})
+
+	t.Run("refund in processing is reconciled once applied", func(t *testing.T) {
+		wfpClient, srv := newClient(t, policy)
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		srv.ProcessNext("REFUND", 1138, "Refund in processing")
+		resp, err := refund(wfpClient, orderReference)
+		require.NoError(t, err)
+		require.Equal(t, wfp.ReasonCodeOk, resp.ReasonCode)
+		require.Equal(t, 1, srv.Calls("REFUND"))
+
+		order, _ := srv.Order(orderReference)
+		require.Equal(t, 40.0, order.RefundedAmount)
+	})
+
+	t.Run("order lookups are part of the call", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		t.Cleanup(srv.Close)
+		var requestIDs []string
+		httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requestIDs = append(requestIDs, req.Header.Get("X-Request-ID"))
+			return srv.Client().Transport.RoundTrip(req)
+		})}
+		var intercepted []string
+		recorder := &recordingObserver{}
+		wfpClient, err := wfp.NewClient(httpClient, merchantLogin, merchantSecret,
+			wfp.WithRetryPolicy(policy),
+			wfp.WithObserver(recorder),
+			wfp.WithInterceptors(func(ctx context.Context, call *wfp.Call, response wfp.Responder, next wfp.Invoker) error {
+				intercepted = append(intercepted, call.TransactionType)
+				if call.Header == nil {
+					call.Header = http.Header{}
+				}
+				call.Header.Set("X-Request-ID", "req-1")
+				return next(ctx, call, response)
+			}),
+		)
+		require.NoError(t, err)
+		orderReference := paidOrder(t, wfpClient, srv)
+		requestIDs, intercepted, recorder.observations = nil, nil, nil
+
+		srv.DropNextResponse("REFUND")
+		_, err = refund(wfpClient, orderReference)
+		require.NoError(t, err)
+
+		// Snapshot, refund and the poll that finds it applied.
+		require.Equal(t, []string{"req-1", "req-1", "req-1"}, requestIDs)
+		require.Equal(t, []string{"REFUND"}, intercepted)
+		require.Len(t, recorder.observations, 1)
+		require.Equal(t, "REFUND", recorder.observations[0].TransactionType)
+		require.Equal(t, 1, recorder.observations[0].Attempts)
+	})
+
+	t.Run("lost invoice answer", func(t *testing.T) {
+		wfpClient, srv := newClient(t, policy)
+		orderReference := uuid.NewString()
+
+		srv.DropNextResponse("CREATE_INVOICE")
+		_, err := wfpClient.CreateInvoiceContext(ctx, newInvoice(wfpClient, orderReference))
+		require.ErrorIs(t, err, wfp.ErrAlreadyApplied)
+		require.Equal(t, 1, srv.Calls("CREATE_INVOICE"))
+		_, ok := srv.Order(orderReference)
+		require.True(t, ok)
+	})
+
+	t.Run("read-only request is repeated until MaxAttempts", func(t *testing.T) {
+		wfpClient, srv := newClient(t, policy)
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		calls := srv.Calls("CHECK_STATUS")
+		srv.FailNext("CHECK_STATUS", 1131, "In processing")
+		srv.FailNext("CHECK_STATUS", 1131, "In processing")
+		status, err := wfpClient.CheckStatus(ctx, orderReference)
+		require.NoError(t, err)
+		require.Equal(t, wayforpaytest.StatusApproved, status.TransactionStatus)
+		require.Equal(t, calls+3, srv.Calls("CHECK_STATUS"))
+
+		for range 3 {
+			srv.DropNextResponse("CHECK_STATUS")
+		}
+		_, err = wfpClient.CheckStatus(ctx, orderReference)
+		var urlErr *url.Error
+		require.ErrorAs(t, err, &urlErr)
+		require.Equal(t, calls+6, srv.Calls("CHECK_STATUS"))
+	})
+
+	t.Run("without a policy a call is made once", func(t *testing.T) {
+		wfpClient, srv := newClient(t, wfp.RetryPolicy{})
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		srv.FailNext("CHECK_STATUS", 1131, "In processing")
+		_, err := wfpClient.CheckStatus(ctx, orderReference)
+		require.ErrorIs(t, err, wfp.ErrTransactionInProcessing)
+		require.Equal(t, 1, srv.Calls("CHECK_STATUS"))
+	})
+
+	t.Run("custom reason codes", func(t *testing.T) {
+		wfpClient, srv := newClient(t, wfp.RetryPolicy{MaxAttempts: 2, RetryableReasonCodes: []int{wayforpaytest.ReasonIllegalOrderState}})
+		orderReference := paidOrder(t, wfpClient, srv)
+
+		calls := srv.Calls("CHECK_STATUS")
+		srv.FailNext("CHECK_STATUS", wayforpaytest.ReasonIllegalOrderState, "Illegal Order State")
+		_, err := wfpClient.CheckStatus(ctx, orderReference)
+		require.NoError(t, err)
+		require.Equal(t, calls+2, srv.Calls("CHECK_STATUS"))
+	})
+}
@@ -0,0 +1,157 @@
+package wayforpay
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// SettleRequest captures funds held by a two-step (AUTH) payment.
+type SettleRequest struct {
+	TransactionType string `json:"transactionType"`
+	MerchantAccount string `json:"merchantAccount"`
+	OrderReference  string `json:"orderReference"`
+	Money
+	MerchantSignature string `json:"merchantSignature"`
+	ApiVersion        int    `json:"apiVersion"`
+}
+
+// NewSettleRequest returns a new SettleRequest.
+func (w *WayForPay) NewSettleRequest() *SettleRequest {
+	return &SettleRequest{
+		TransactionType: "SETTLE",
+		MerchantAccount: w.merchantLogin,
+		ApiVersion:      w.apiVersion,
+	}
+}
+
+// Settle captures the held payment. The amount may be lower than the held one.
+func (w *WayForPay) Settle(ctx context.Context, request *SettleRequest) (*SettleResponse, error) {
+	return Do[*SettleRequest, SettleResponse](ctx, w, request)
+}
+
+func (s *SettleRequest) validate() error {
+	if s.TransactionType == "" {
+		return ErrTransactionTypeRequired
+	}
+	if s.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if s.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	if err := s.Money.check(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *SettleRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (s *SettleRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (s *SettleRequest) transaction() (transactionType, orderReference string) {
+	return s.TransactionType, s.OrderReference
+}
+
+// reconcile treats a hold that became approved since the first attempt as
+// captured by it and answers with the order status.
+func (s *SettleRequest) reconcile(before, after *CheckStatusResponse, response Responder) (bool, error) {
+	if before == nil || after == nil || before.TransactionStatus != "WaitingAuthComplete" ||
+		after.TransactionStatus != "Approved" {
+		return false, nil
+	}
+	if res, ok := response.(*SettleResponse); ok {
+		*res = SettleResponse{
+			MerchantAccount:   after.MerchantAccount,
+			OrderReference:    after.OrderReference,
+			TransactionStatus: after.TransactionStatus,
+			Reason:            "Ok",
+			ReasonCode:        ReasonCodeOk,
+		}
+	}
+	return true, nil
+}
+
+func (s *SettleRequest) signatureFields() []string {
+	return []string{
+		s.MerchantAccount,
+		s.OrderReference,
+		s.Amount.String(),
+		s.Currency,
+	}
+}
+
+func (s *SettleRequest) setSignature(signature string) {
+	s.MerchantSignature = signature
+}
+
+func (s *SettleRequest) SetMerchantAccount(merchantAccount string) *SettleRequest {
+	s.MerchantAccount = merchantAccount
+	return s
+}
+
+func (s *SettleRequest) SetOrderReference(orderReference string) *SettleRequest {
+	s.OrderReference = orderReference
+	return s
+}
+
+// SetAmount sets the captured amount. It must not exceed the held amount.
+func (s *SettleRequest) SetAmount(amount Amount) *SettleRequest {
+	s.Amount = amount
+	return s
+}
+
+// SetMoney sets the amount and the currency.
+func (s *SettleRequest) SetMoney(money Money) *SettleRequest {
+	s.Money = money
+	return s
+}
+
+func (s *SettleRequest) SetCurrency(currency string) *SettleRequest {
+	s.Currency = currency
+	return s
+}
+
+func (s *SettleRequest) SetApiVersion(apiVersion int) *SettleRequest {
+	s.ApiVersion = apiVersion
+	return s
+}
+
+type SettleResponse struct {
+	MerchantAccount   string `json:"merchantAccount"`
+	OrderReference    string `json:"orderReference"`
+	MerchantSignature string `json:"merchantSignature"`
+	TransactionStatus string `json:"transactionStatus"`
+	Reason            string `json:"reason"`
+	ReasonCode        int    `json:"reasonCode"`
+}
+
+// UnmarshalJSON decodes the SETTLE answer, accepting a reason code sent as a string.
+func (s *SettleResponse) UnmarshalJSON(data []byte) error {
+	type alias SettleResponse
+	aux := struct {
+		*alias
+		ReasonCode jsonInt `json:"reasonCode"`
+	}{alias: (*alias)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.ReasonCode = int(aux.ReasonCode)
+	return nil
+}
+
+func (s *SettleResponse) Error() error {
+	return reasonError(s.ReasonCode, s.Reason)
+}
+
+func (s *SettleResponse) GetReasonCode() int {
+	return s.ReasonCode
+}
+
+func (s *SettleResponse) GetReason() string {
+	return s.Reason
+}
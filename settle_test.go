@@ -0,0 +1,52 @@
+package wayforpay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_Settle(t *testing.T) {
+	wfpClient, srv := newTestClient(t)
+	ctx := context.Background()
+
+	orderReference := uuid.NewString()
+	_, err := wfpClient.CreateInvoiceContext(ctx, wfpClient.NewCreateInvoiceRequest().
+		SetMerchantTransactionType("AUTH").
+		SetMerchantDomainName("test.com").
+		SetOrderDate(time.Now()).
+		SetAmount(wfp.MustParseAmount("100")).
+		SetCurrency("UAH").
+		SetOrderReference(orderReference).
+		AddProduct("test", wfp.MustParseAmount("100"), 1))
+	require.NoError(t, err)
+
+	settle := func(amount string) (*wfp.SettleResponse, error) {
+		return wfpClient.Settle(ctx, wfpClient.NewSettleRequest().
+			SetOrderReference(orderReference).
+			SetAmount(wfp.MustParseAmount(amount)).
+			SetCurrency("UAH"))
+	}
+
+	_, err = settle("80.50")
+	require.ErrorIs(t, err, wfp.ErrIllegalOrderState)
+
+	require.NoError(t, srv.Approve(orderReference))
+	_, err = settle("150")
+	require.ErrorIs(t, err, wfp.ErrInvalidAmount)
+
+	resp, err := settle("80.50")
+	require.NoError(t, err)
+	require.Equal(t, wayforpaytest.StatusApproved, resp.TransactionStatus)
+
+	order, _ := srv.Order(orderReference)
+	require.Equal(t, 80.5, order.Amount)
+
+	_, err = wfpClient.Settle(ctx, wfpClient.NewSettleRequest().SetOrderReference(orderReference))
+	require.ErrorIs(t, err, wfp.ErrAmountRequired)
+}
@@ -0,0 +1,47 @@
+package wayforpay
+
+import (
+	"time"
+)
+
+// Timeout is a period in whole seconds, the unit WayForPay expects for
+// orderTimeout, holdTimeout and orderLifetime. It encodes as a JSON integer.
+// The zero value means not set, so WayForPay applies its default.
+type Timeout int64
+
+const (
+	// MinTimeout and MaxTimeout are the limits WayForPay accepts for a timeout.
+	MinTimeout Timeout = 60
+	MaxTimeout Timeout = 30 * 24 * 60 * 60
+
+	// DefaultOrderTimeout is the orderTimeout WayForPay applies to invoices.
+	DefaultOrderTimeout Timeout = 24 * 60 * 60
+)
+
+// NewTimeout converts d to whole seconds, rounding down.
+func NewTimeout(d time.Duration) Timeout {
+	return Timeout(d / time.Second)
+}
+
+// Duration returns the timeout as a time.Duration.
+func (t Timeout) Duration() time.Duration {
+	return time.Duration(t) * time.Second
+}
+
+// Validate reports an unset timeout as valid and a set one outside
+// MinTimeout..MaxTimeout as ErrInvalidTimeout.
+func (t Timeout) Validate() error {
+	if t != 0 && (t < MinTimeout || t > MaxTimeout) {
+		return ErrInvalidTimeout
+	}
+	return nil
+}
+
+func validateTimeouts(timeouts ...Timeout) error {
+	for _, t := range timeouts {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package wayforpay
+
+import (
+	"context"
+)
+
+// SpanTracer starts spans. Together with Span it is the part of a tracing API
+// that TracingObserver needs, so the SDK does not depend on one. The
+// wayforpayotel module adapts an OpenTelemetry trace.Tracer.
+type SpanTracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a SpanTracer.
+type Span interface {
+	// SetAttribute sets an attribute; value is a string or an int.
+	SetAttribute(key string, value any)
+	// RecordError records err and marks the span as failed.
+	RecordError(err error)
+	End()
+}
+
+// Span attribute keys set by TracingObserver.
+const (
+	AttrTransactionType = "wayforpay.transaction_type"
+	AttrOrderReference  = "wayforpay.order_reference"
+	AttrReasonCode      = "wayforpay.reason_code"
+	AttrAttempts        = "wayforpay.attempts"
+	AttrHTTPStatus      = "http.response.status_code"
+)
+
+// TracingObserver wraps every call in a span named "wayforpay " followed by the
+// transaction type. Spans of the HTTP client, if it is instrumented, become
+// its children. Use it by pointer; every TracingObserver keeps its own spans,
+// so several can be combined with MultiObserver.
+type TracingObserver struct {
+	Tracer SpanTracer
+}
+
+// spanKey is the context key of the span started by observer.
+type spanKey struct{ observer *TracingObserver }
+
+func (t *TracingObserver) Start(ctx context.Context, call *Call) context.Context {
+	ctx, span := t.Tracer.StartSpan(ctx, "wayforpay "+call.TransactionType)
+	span.SetAttribute(AttrTransactionType, call.TransactionType)
+	if call.OrderReference != "" {
+		span.SetAttribute(AttrOrderReference, call.OrderReference)
+	}
+	return context.WithValue(ctx, spanKey{t}, span)
+}
+
+func (t *TracingObserver) Finish(ctx context.Context, o Observation) {
+	span, ok := ctx.Value(spanKey{t}).(Span)
+	if !ok {
+		return
+	}
+	if o.HTTPStatus != 0 {
+		span.SetAttribute(AttrHTTPStatus, o.HTTPStatus)
+	}
+	if o.ReasonCode != 0 {
+		span.SetAttribute(AttrReasonCode, o.ReasonCode)
+	}
+	span.SetAttribute(AttrAttempts, o.Attempts)
+	if o.Err != nil {
+		span.RecordError(o.Err)
+	}
+	span.End()
+}
@@ -0,0 +1,177 @@
+package wayforpay
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// MaxTransactionListWindow is the longest period a single TRANSACTION_LIST request may cover.
+const MaxTransactionListWindow = 31 * 24 * time.Hour
+
+type TransactionListRequest struct {
+	TransactionType   string `json:"transactionType"`
+	MerchantAccount   string `json:"merchantAccount"`
+	MerchantSignature string `json:"merchantSignature"`
+	ApiVersion        int    `json:"apiVersion"`
+	DateBegin         int64  `json:"dateBegin"`
+	DateEnd           int64  `json:"dateEnd"`
+}
+
+func (w *WayForPay) newTransactionListRequest(from, to time.Time) *TransactionListRequest {
+	return &TransactionListRequest{
+		TransactionType: "TRANSACTION_LIST",
+		MerchantAccount: w.merchantLogin,
+		ApiVersion:      w.apiVersion,
+		DateBegin:       from.Unix(),
+		DateEnd:         to.Unix(),
+	}
+}
+
+func (t *TransactionListRequest) validate() error {
+	if t.TransactionType == "" {
+		return ErrTransactionTypeRequired
+	}
+	if t.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if t.DateEnd < t.DateBegin || time.Duration(t.DateEnd-t.DateBegin)*time.Second > MaxTransactionListWindow {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+func (t *TransactionListRequest) params() (Params, error) {
+	return Params{}, nil
+}
+
+func (t *TransactionListRequest) method() (API, string) {
+	return APIMain, ""
+}
+
+func (t *TransactionListRequest) transaction() (transactionType, orderReference string) {
+	return t.TransactionType, ""
+}
+
+func (t *TransactionListRequest) idempotent() bool {
+	return true
+}
+
+func (t *TransactionListRequest) signatureFields() []string {
+	return []string{
+		t.MerchantAccount,
+		strconv.FormatInt(t.DateBegin, 10),
+		strconv.FormatInt(t.DateEnd, 10),
+	}
+}
+
+func (t *TransactionListRequest) setSignature(signature string) {
+	t.MerchantSignature = signature
+}
+
+// Transaction is a single entry of the merchant transaction list.
+type Transaction struct {
+	TransactionType string    `json:"transactionType"`
+	OrderReference  string    `json:"orderReference"`
+	CreatedDate     time.Time `json:"createdDate"`
+	ProcessingDate  time.Time `json:"processingDate"`
+	Money
+	TransactionStatus string    `json:"transactionStatus"`
+	Reason            string    `json:"reason"`
+	ReasonCode        int       `json:"reasonCode"`
+	SettlementDate    time.Time `json:"settlementDate"`
+	SettlementAmount  Amount    `json:"settlementAmount"`
+	Fee               Amount    `json:"fee"`
+	CardPan           string    `json:"cardPan"`
+	CardType          string    `json:"cardType"`
+	IssuerBankCountry string    `json:"issuerBankCountry"`
+	IssuerBankName    string    `json:"issuerBankName"`
+	PaymentSystem     string    `json:"paymentSystem"`
+	Email             string    `json:"email"`
+	Phone             string    `json:"phone"`
+}
+
+// UnmarshalJSON decodes the transaction, accepting unix timestamps and numbers sent as strings.
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type alias Transaction
+	aux := struct {
+		*alias
+		CreatedDate    jsonTime `json:"createdDate"`
+		ProcessingDate jsonTime `json:"processingDate"`
+		ReasonCode     jsonInt  `json:"reasonCode"`
+		SettlementDate jsonTime `json:"settlementDate"`
+		Fee            jsonFee  `json:"fee"`
+	}{alias: (*alias)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.CreatedDate = time.Time(aux.CreatedDate)
+	t.ProcessingDate = time.Time(aux.ProcessingDate)
+	t.ReasonCode = int(aux.ReasonCode)
+	t.SettlementDate = time.Time(aux.SettlementDate)
+	t.Fee = Amount(aux.Fee)
+	return nil
+}
+
+type TransactionListResponse struct {
+	Reason          string        `json:"reason"`
+	ReasonCode      int           `json:"reasonCode"`
+	TransactionList []Transaction `json:"transactionList"`
+}
+
+func (t *TransactionListResponse) Error() error {
+	return reasonError(t.ReasonCode, t.Reason)
+}
+
+func (t *TransactionListResponse) GetReasonCode() int {
+	return t.ReasonCode
+}
+
+func (t *TransactionListResponse) GetReason() string {
+	return t.Reason
+}
+
+func (w *WayForPay) transactionListWindow(ctx context.Context, from, to time.Time) (*TransactionListResponse, error) {
+	request := w.newTransactionListRequest(from, to)
+	return Do[*TransactionListRequest, TransactionListResponse](ctx, w, request)
+}
+
+// TransactionList iterates over the transactions processed between from and to,
+// both inclusive. Long periods are split into MaxTransactionListWindow windows
+// which are requested one at a time, as the iteration reaches them.
+//
+//	for t, err := range client.TransactionList(ctx, from, to) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (w *WayForPay) TransactionList(ctx context.Context, from, to time.Time) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		from, to = from.Truncate(time.Second), to.Truncate(time.Second)
+		if to.Before(from) {
+			yield(Transaction{}, ErrInvalidDateRange)
+			return
+		}
+		for start := from; !start.After(to); {
+			end := start.Add(MaxTransactionListWindow)
+			if end.After(to) {
+				end = to
+			}
+			resp, err := w.transactionListWindow(ctx, start, end)
+			if err != nil {
+				yield(Transaction{}, err)
+				return
+			}
+			for _, t := range resp.TransactionList {
+				if !yield(t, nil) {
+					return
+				}
+			}
+			// dates have a one second resolution and both ends are inclusive
+			start = end.Add(time.Second)
+		}
+	}
+}
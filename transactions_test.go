@@ -0,0 +1,51 @@
+package wayforpay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_TransactionList(t *testing.T) {
+	wfpClient, srv := newTestClient(t)
+	ctx := context.Background()
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(80 * 24 * time.Hour)
+	for i, offset := range []time.Duration{0, 31 * 24 * time.Hour, 31*24*time.Hour + time.Second, 62 * 24 * time.Hour, 80 * 24 * time.Hour} {
+		srv.AddTransaction(wayforpaytest.Transaction{
+			TransactionType:   "SALE",
+			OrderReference:    string(rune('A' + i)),
+			Amount:            100,
+			Currency:          "UAH",
+			TransactionStatus: wayforpaytest.StatusApproved,
+			ReasonCode:        1100,
+			Reason:            "Ok",
+			CreatedDate:       from.Add(offset),
+			ProcessingDate:    from.Add(offset),
+		})
+	}
+	srv.AddTransaction(wayforpaytest.Transaction{OrderReference: "outside", ProcessingDate: to.Add(time.Second)})
+
+	var got []string
+	for tr, err := range wfpClient.TransactionList(ctx, from, to) {
+		require.NoError(t, err)
+		require.Equal(t, wfp.MustParseAmount("100"), tr.Amount)
+		got = append(got, tr.OrderReference)
+	}
+	require.Equal(t, []string{"A", "B", "C", "D", "E"}, got)
+	require.Equal(t, 3, srv.Calls("TRANSACTION_LIST"))
+
+	for range wfpClient.TransactionList(ctx, from, to) {
+		break
+	}
+	require.Equal(t, 4, srv.Calls("TRANSACTION_LIST"))
+
+	for _, err := range wfpClient.TransactionList(ctx, to, from) {
+		require.ErrorIs(t, err, wfp.ErrInvalidDateRange)
+	}
+}
@@ -1,16 +1,44 @@
 package wayforpay
 
 import (
-	"fmt"
-	"io"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
 )
 
 type Params map[string]string
 
+// API identifies a WayForPay API, each with its own base url.
+type API int
+
+const (
+	// APIMain is the main API, DefaultBaseURL or the url set by WithBaseURL.
+	APIMain API = iota
+	// APIRegular is the regular payments API, DefaultRegularURL or the url set
+	// by WithRegularURL.
+	APIRegular
+)
+
+// Payment is a WayForPay transaction request. Do resolves its endpoint from
+// method, signs signatureFields, validates and encodes it; a new transaction
+// type only declares these.
 type Payment interface {
 	params() (Params, error)
-	method() string
-	body(secret string) io.Reader
+	// method returns the API the request is posted to and the path relative
+	// to its base url, or an absolute url.
+	method() (api API, path string)
+	signatureFields() []string
+	setSignature(signature string)
+	validate() error
+	transaction() (transactionType, orderReference string)
+}
+
+// signature returns the HMAC-MD5 merchantSignature of fields joined with ";".
+func signature(secret string, fields []string) string {
+	h := hmac.New(md5.New, []byte(secret))
+	h.Write([]byte(strings.Join(fields, ";")))
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 type APIResponse struct {
@@ -19,10 +47,7 @@ type APIResponse struct {
 }
 
 func (r *APIResponse) Error() error {
-	if r.ReasonCode != 1100 {
-		return fmt.Errorf("%d: %s", r.ReasonCode, r.Reason)
-	}
-	return nil
+	return reasonError(r.ReasonCode, r.Reason)
 }
 
 func (r *APIResponse) GetReasonCode() int {
@@ -0,0 +1,225 @@
+package wayforpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// VerifyRequest asks the customer to enter a card on the WayForPay verification
+// page without a purchase. The verification amount is held and released right away.
+type VerifyRequest struct {
+	MerchantAccount    string
+	MerchantAuthType   SignatureMode
+	MerchantDomainName string
+	MerchantSignature  string
+	ApiVersion         int
+	Language           string
+	OrderReference     string
+	Money
+	ServiceUrl  string
+	ReturnUrl   string
+	ClientEmail string
+	ClientPhone string
+}
+
+// NewVerifyRequest returns a new VerifyRequest. Default amount: 1 UAH
+func (w *WayForPay) NewVerifyRequest() *VerifyRequest {
+	return &VerifyRequest{
+		MerchantAccount:  w.merchantLogin,
+		MerchantAuthType: SignatureModeSimple,
+		ApiVersion:       w.apiVersion,
+		Language:         w.language,
+		Money:            NewMoney(NewAmount(100), "UAH"),
+	}
+}
+
+func (v *VerifyRequest) SetMerchantDomainName(merchantDomainName string) *VerifyRequest {
+	v.MerchantDomainName = merchantDomainName
+	return v
+}
+
+func (v *VerifyRequest) SetOrderReference(orderReference string) *VerifyRequest {
+	v.OrderReference = orderReference
+	return v
+}
+
+func (v *VerifyRequest) SetAmount(amount Amount) *VerifyRequest {
+	v.Amount = amount
+	return v
+}
+
+// SetMoney sets the amount and the currency.
+func (v *VerifyRequest) SetMoney(money Money) *VerifyRequest {
+	v.Money = money
+	return v
+}
+
+func (v *VerifyRequest) SetCurrency(currency string) *VerifyRequest {
+	v.Currency = currency
+	return v
+}
+
+// SetServiceUrl sets the url WayForPay posts the verification result to.
+func (v *VerifyRequest) SetServiceUrl(serviceUrl string) *VerifyRequest {
+	v.ServiceUrl = serviceUrl
+	return v
+}
+
+// SetReturnUrl sets the url the customer is returned to after the verification.
+func (v *VerifyRequest) SetReturnUrl(returnUrl string) *VerifyRequest {
+	v.ReturnUrl = returnUrl
+	return v
+}
+
+// SetLanguage sets the verification page language.
+// Possible values: RU, UA, EN
+func (v *VerifyRequest) SetLanguage(language string) *VerifyRequest {
+	v.Language = language
+	return v
+}
+
+func (v *VerifyRequest) SetClientEmail(clientEmail string) *VerifyRequest {
+	v.ClientEmail = clientEmail
+	return v
+}
+
+func (v *VerifyRequest) SetClientPhone(clientPhone string) *VerifyRequest {
+	v.ClientPhone = clientPhone
+	return v
+}
+
+func (v *VerifyRequest) sign(secret string) {
+	data := []string{
+		v.MerchantAccount,
+		v.MerchantDomainName,
+		v.OrderReference,
+		v.Amount.String(),
+		v.Currency,
+	}
+	v.MerchantSignature = signature(secret, data)
+}
+
+func (v *VerifyRequest) validate() error {
+	if v.MerchantAccount == "" {
+		return ErrMerchantAccountRequired
+	}
+	if v.MerchantDomainName == "" {
+		return ErrMerchantDomainNameRequired
+	}
+	if v.MerchantSignature == "" {
+		return ErrMerchantSignatureRequired
+	}
+	if v.OrderReference == "" {
+		return ErrOrderReferenceRequired
+	}
+	if err := v.Money.check(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v *VerifyRequest) fields() url.Values {
+	f := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			f.Set(key, value)
+		}
+	}
+	set("merchantAccount", v.MerchantAccount)
+	set("merchantAuthType", string(v.MerchantAuthType))
+	set("merchantDomainName", v.MerchantDomainName)
+	set("merchantSignature", v.MerchantSignature)
+	set("apiVersion", strconv.Itoa(v.ApiVersion))
+	set("language", v.Language)
+	set("orderReference", v.OrderReference)
+	set("amount", v.Amount.String())
+	set("currency", v.Currency)
+	set("serviceUrl", v.ServiceUrl)
+	set("returnUrl", v.ReturnUrl)
+	set("clientEmail", v.ClientEmail)
+	set("clientPhone", v.ClientPhone)
+	return f
+}
+
+// VerifyForm signs and validates the request and returns the form for the verification page.
+func (w *WayForPay) VerifyForm(request *VerifyRequest) (*Form, error) {
+	request.sign(w.merchantSecret)
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	return &Form{
+		Action: w.verifyURL,
+		Fields: request.fields(),
+	}, nil
+}
+
+// VerifyResult is the outcome of a card verification.
+type VerifyResult struct {
+	OrderReference    string    `json:"orderReference"`
+	RecToken          string    `json:"recToken"`
+	CardPan           string    `json:"cardPan"`
+	CardType          string    `json:"cardType"`
+	IssuerBankCountry string    `json:"issuerBankCountry"`
+	IssuerBankName    string    `json:"issuerBankName"`
+	TransactionStatus string    `json:"transactionStatus"`
+	Reason            string    `json:"reason"`
+	ReasonCode        int       `json:"reasonCode"`
+	ProcessingDate    time.Time `json:"processingDate"`
+}
+
+func newVerifyResult(n *Notification) *VerifyResult {
+	return &VerifyResult{
+		OrderReference:    n.OrderReference,
+		RecToken:          n.RecToken,
+		CardPan:           n.CardPan,
+		CardType:          n.CardType,
+		IssuerBankCountry: n.IssuerBankCountry,
+		IssuerBankName:    n.IssuerBankName,
+		TransactionStatus: n.TransactionStatus,
+		Reason:            n.Reason,
+		ReasonCode:        n.ReasonCode,
+		ProcessingDate:    n.ProcessingDate,
+	}
+}
+
+// Verified reports whether the card was verified and RecToken can be used for charges.
+func (v *VerifyResult) Verified() bool {
+	return v.ReasonCode == ReasonCodeOk && v.RecToken != ""
+}
+
+func (v *VerifyResult) Error() error {
+	return reasonError(v.ReasonCode, v.Reason)
+}
+
+func (v *VerifyResult) GetReasonCode() int {
+	return v.ReasonCode
+}
+
+func (v *VerifyResult) GetReason() string {
+	return v.Reason
+}
+
+// ParseVerification decodes the verification result posted to the serviceUrl and
+// verifies its merchantSignature. A declined verification is not a parse error;
+// check Verified or Error.
+func (w *WayForPay) ParseVerification(r *http.Request) (*VerifyResult, error) {
+	n, err := w.ParseNotification(r)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifyResult(n), nil
+}
+
+// VerificationHandlerFunc processes a verified card verification result.
+type VerificationHandlerFunc func(ctx context.Context, v *VerifyResult) error
+
+// VerificationHandler returns an http.Handler for the verification serviceUrl.
+// It behaves like NotificationHandler.
+func (w *WayForPay) VerificationHandler(fn VerificationHandlerFunc) http.Handler {
+	return w.NotificationHandler(func(ctx context.Context, n *Notification) error {
+		return fn(ctx, newVerifyResult(n))
+	})
+}
@@ -0,0 +1,55 @@
+package wayforpay_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_Verify(t *testing.T) {
+	wfpClient, err := wfp.NewClient(nil, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+
+	form, err := wfpClient.VerifyForm(wfpClient.NewVerifyRequest().
+		SetMerchantDomainName("test.com").
+		SetOrderReference("VRF-1").
+		SetServiceUrl("https://test.com/verify/callback"))
+	require.NoError(t, err)
+	require.Equal(t, wfp.DefaultVerifyURL, form.Action)
+	require.Equal(t, sign(merchantSecret, merchantLogin, "test.com", "VRF-1", "1", "UAH"), form.Fields.Get("merchantSignature"))
+	require.Equal(t, "https://test.com/verify/callback", form.Fields.Get("serviceUrl"))
+
+	cases := []struct {
+		name         string
+		status       string
+		reasonCode   string
+		recToken     string
+		wantVerified bool
+	}{
+		{name: "verified", status: "Approved", reasonCode: "1100", recToken: "tok", wantVerified: true},
+		{name: "declined", status: "Declined", reasonCode: "1105"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := sign(merchantSecret, merchantLogin, "VRF-1", "1", "UAH", "", "41****8217", tt.status, tt.reasonCode)
+			body := `{"merchantAccount":"test_merch_n1","orderReference":"VRF-1","merchantSignature":"` + signature + `",` +
+				`"amount":1,"currency":"UAH","cardPan":"41****8217","cardType":"Visa","transactionStatus":"` + tt.status + `",` +
+				`"reasonCode":` + tt.reasonCode + `,"recToken":"` + tt.recToken + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/verify/callback", strings.NewReader(body))
+
+			got, err := wfpClient.ParseVerification(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVerified, got.Verified())
+			require.Equal(t, "41****8217", got.CardPan)
+			require.Equal(t, "Visa", got.CardType)
+			require.Equal(t, tt.recToken, got.RecToken)
+			if !tt.wantVerified {
+				require.ErrorIs(t, got.Error(), wfp.ErrInvalidCard)
+			}
+		})
+	}
+}
@@ -1,20 +1,45 @@
 package wayforpay
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type WayForPay struct {
-	client         *http.Client
-	merchantLogin  string
-	merchantSecret string
+	client              *http.Client
+	merchantLogin       string
+	merchantSecret      string
+	merchantPassword    string
+	authorizationTicket string
+	baseURL             string
+	regularURL          string
+	purchaseURL         string
+	verifyURL           string
+	userAgent           string
+	apiVersion          int
+	language            string
+	now                 func() time.Time
+	logger              *slog.Logger
+	logLevel            slog.Level
+	errorLogLevel       slog.Level
+	redaction           RedactionPolicy
+	ratesTTL            time.Duration
+	rates               ratesCache
+	retry               RetryPolicy
+	maxResponseSize     int64
+	interceptors        []Interceptor
+	observer            Observer
 }
 
-func NewClient(httpClient *http.Client, merchantLogin, merchantSecret string) (*WayForPay, error) {
+func NewClient(httpClient *http.Client, merchantLogin, merchantSecret string, opts ...Option) (*WayForPay, error) {
 	if httpClient == nil {
 		httpClient = &http.Client{}
 	}
@@ -24,11 +49,28 @@ func NewClient(httpClient *http.Client, merchantLogin, merchantSecret string) (*
 	if merchantSecret == "" {
 		return nil, ErrMerchantSecretRequired
 	}
-	return &WayForPay{
-		client:         httpClient,
-		merchantLogin:  merchantLogin,
-		merchantSecret: merchantSecret,
-	}, nil
+	w := &WayForPay{
+		client:          httpClient,
+		merchantLogin:   merchantLogin,
+		merchantSecret:  merchantSecret,
+		baseURL:         DefaultBaseURL,
+		regularURL:      DefaultRegularURL,
+		purchaseURL:     DefaultPurchaseURL,
+		verifyURL:       DefaultVerifyURL,
+		userAgent:       DefaultUserAgent,
+		apiVersion:      DefaultAPIVersion,
+		language:        DefaultLanguage,
+		now:             time.Now,
+		maxResponseSize: DefaultMaxResponseSize,
+		logLevel:        slog.LevelInfo,
+		errorLogLevel:   slog.LevelWarn,
+		redaction:       DefaultRedactionPolicy(),
+		observer:        NopObserver{},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
 }
 
 func buildParams(in Params) url.Values {
@@ -43,34 +85,152 @@ func buildParams(in Params) url.Values {
 	return out
 }
 
-func (w *WayForPay) makeRequest(endpoint string, body io.Reader, response Responder, params Params) error {
-	method := fmt.Sprintf(APIEndpoint, endpoint)
-	rawUrl, err := url.Parse(method)
+// Do executes request and decodes the answer into a new Resp. It is the one
+// path every transaction takes: the call passes the interceptors, the endpoint
+// is resolved once, the request is signed, validated and encoded, and a
+// declined answer is returned as an *APIError carrying the transaction type
+// and order reference.
+func Do[Req Payment, Resp any, PResp interface {
+	*Resp
+	Responder
+}](ctx context.Context, w *WayForPay, request Req) (*Resp, error) {
+	response := PResp(new(Resp))
+	if err := w.do(ctx, request, response); err != nil {
+		return nil, err
+	}
+	return (*Resp)(response), nil
+}
+
+func (w *WayForPay) do(ctx context.Context, request Payment, response Responder) error {
+	invoke := w.invoke
+	if w.logger != nil {
+		invoke = func(ctx context.Context, call *Call, response Responder) error {
+			return w.logCall(ctx, call, response, w.invoke)
+		}
+	}
+	call := newCall(request)
+	ctx = w.observer.Start(ctx, call)
+	start := time.Now()
+	err := w.intercept(ctx, call, response, invoke)
+	w.observer.Finish(ctx, Observation{
+		TransactionType: call.TransactionType,
+		OrderReference:  call.OrderReference,
+		Latency:         time.Since(start),
+		HTTPStatus:      call.httpStatus,
+		ReasonCode:      reasonCode(response, err),
+		Attempts:        call.attempts,
+		Err:             err,
+	})
+	return err
+}
+
+// invoke signs, validates and sends the request of call.
+func (w *WayForPay) invoke(ctx context.Context, call *Call, response Responder) error {
+	request := call.payment
+	body, params, err := w.encode(request)
+	if err != nil {
+		return err
+	}
+	if err := w.sendWithRetry(ctx, call, w.endpoint(request), body, response, params); err != nil {
+		transactionType, orderReference := request.transaction()
+		return withRequest(err, transactionType, orderReference)
+	}
+	return nil
+}
+
+// encode signs and validates request and returns its body and query parameters.
+func (w *WayForPay) encode(request Payment) ([]byte, Params, error) {
+	if fields := request.signatureFields(); fields != nil {
+		request.setSignature(signature(w.merchantSecret, fields))
+	}
+	if err := request.validate(); err != nil {
+		return nil, nil, err
+	}
+	params, err := request.params()
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, params, nil
+}
+
+// endpoint returns the url request is posted to. An absolute path is used as is.
+func (w *WayForPay) endpoint(request Payment) string {
+	api, path := request.method()
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	if api == APIRegular {
+		return w.regularURL + path
+	}
+	return w.baseURL + path
+}
+
+// send posts body with the extra headers of call to endpoint and decodes the
+// answer into response.
+func (w *WayForPay) send(ctx context.Context, call *Call, endpoint string, body []byte, response Responder, params Params) error {
+	rawUrl, err := url.Parse(endpoint)
 	if err != nil {
 		return err
 	}
 	rawUrl.RawQuery = buildParams(params).Encode()
-	method = rawUrl.String()
 
-	req, err := http.NewRequest(http.MethodPost, method, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawUrl.String(), bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
+	for key, values := range call.Header {
+		req.Header[key] = values
+	}
 	req.Header.Set("Content-Type", "application/json")
+	if w.userAgent != "" {
+		req.Header.Set("User-Agent", w.userAgent)
+	}
 
 	res, err := w.client.Do(req)
 	if err != nil {
-		return err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return &TransportError{Err: err}
 	}
 	defer res.Body.Close()
+	call.httpStatus = res.StatusCode
 
-	respBody, err := io.ReadAll(res.Body)
+	respBody, err := io.ReadAll(io.LimitReader(res.Body, w.maxResponseSize+1))
+	transportErr := &TransportError{
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+		Body:        snippet(respBody),
+	}
+	switch {
+	case err != nil:
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		transportErr.Err = fmt.Errorf("%w: %w", ErrTruncatedResponse, err)
+		return transportErr
+	case int64(len(respBody)) > w.maxResponseSize:
+		transportErr.Err = ErrResponseTooLarge
+		return transportErr
+	case res.ContentLength > 0 && int64(len(respBody)) < res.ContentLength:
+		transportErr.Err = ErrTruncatedResponse
+		return transportErr
+	case !IsSuccessHttpCode(res.StatusCode):
+		transportErr.Err = ErrUnexpectedStatus
+		return transportErr
+	}
 
 	if err := json.Unmarshal(respBody, &response); err != nil {
-		return err
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = ErrNonJSONResponse
+		}
+		transportErr.Err = err
+		return transportErr
 	}
-	if response.GetReasonCode() != 1100 {
-		return fmt.Errorf("api error: code: %v, reason: %v", response.GetReasonCode(), response.GetReason())
-	}
-	return nil
+	return response.Error()
 }
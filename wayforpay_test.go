@@ -0,0 +1,329 @@
+package wayforpay_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWayForPay_ContextCancellation(t *testing.T) {
+	blocking := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}),
+	}
+	wfpClient, err := wfp.NewClient(blocking, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{
+			name: "check status",
+			call: func(ctx context.Context) error {
+				_, err := wfpClient.CheckStatus(ctx, "AAA")
+				return err
+			},
+		},
+		{
+			name: "create invoice",
+			call: func(ctx context.Context) error {
+				_, err := wfpClient.CreateInvoiceContext(ctx, wfpClient.NewCreateInvoiceRequest().
+					SetMerchantDomainName("test.com").
+					SetOrderReference("AAA").
+					SetOrderDate(time.Now()).
+					SetAmount(wfp.MustParseAmount("100")).
+					SetCurrency("UAH").
+					AddProduct("test", wfp.MustParseAmount("100"), 1))
+				return err
+			},
+		},
+		{
+			name: "remove invoice",
+			call: func(ctx context.Context) error {
+				_, err := wfpClient.RemoveInvoiceContext(ctx, wfpClient.NewRemoveInvoiceRequest().
+					SetMerchantAccount(merchantLogin).
+					SetOrderReference("AAA"))
+				return err
+			},
+		},
+		{
+			name: "refund",
+			call: func(ctx context.Context) error {
+				_, err := wfpClient.CreateRefundContext(ctx, wfpClient.NewRefundRequest().
+					SetOrderReference("AAA").
+					SetAmount(wfp.MustParseAmount("100")).
+					SetCurrency("UAH"))
+				return err
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err := tt.call(ctx)
+			require.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+		})
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	var got *http.Request
+	recorder := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			got = req
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"reasonCode":1100,"reason":"Ok"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(recorder, merchantLogin, merchantSecret,
+		wfp.WithBaseURL("https://proxy.example.com/wayforpay/"),
+		wfp.WithUserAgent("shop/1.0"),
+		wfp.WithAPIVersion(2),
+		wfp.WithLanguage("UA"),
+	)
+	require.NoError(t, err)
+
+	invoice := wfpClient.NewCreateInvoiceRequest()
+	require.Equal(t, "2", invoice.ApiVersion)
+	require.Equal(t, "UA", invoice.Language)
+	require.Equal(t, 2, wfpClient.NewRefundRequest().ApiVersion)
+
+	_, err = wfpClient.CheckStatus(context.Background(), "AAA")
+	require.NoError(t, err)
+	require.Equal(t, "https://proxy.example.com/wayforpay", got.URL.String())
+	require.Equal(t, "shop/1.0", got.Header.Get("User-Agent"))
+}
+
+func TestNewClient_WithBaseURL(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	defer srv.Close()
+	srv.AddOrder(wayforpaytest.Order{OrderReference: "AAA", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved, ReasonCode: 1100, Reason: "Ok"})
+
+	wfpClient, err := wfp.NewClient(nil, merchantLogin, merchantSecret, wfp.WithBaseURL(srv.APIURL))
+	require.NoError(t, err)
+
+	status, err := wfpClient.CheckStatus(context.Background(), "AAA")
+	require.NoError(t, err)
+	require.Equal(t, wfp.MustParseAmount("100"), status.Amount)
+}
+
+func TestDo(t *testing.T) {
+	var urls []string
+	acquirer := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"orderReference":"AAA","transactionStatus":"Approved","reasonCode":1100,"reason":"Ok"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	wfpClient, err := wfp.NewClient(acquirer, merchantLogin, merchantSecret)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	settle := wfpClient.NewSettleRequest().
+		SetOrderReference("AAA").
+		SetAmount(wfp.MustParseAmount("10")).
+		SetCurrency("UAH")
+	resp, err := wfp.Do[*wfp.SettleRequest, wfp.SettleResponse](ctx, wfpClient, settle)
+	require.NoError(t, err)
+	require.Equal(t, "Approved", resp.TransactionStatus)
+	require.Equal(t, sign(merchantSecret, merchantLogin, "AAA", "10", "UAH"), settle.MerchantSignature)
+
+	_, err = wfpClient.RemoveInvoice(wfpClient.NewRemoveInvoiceRequest().SetMerchantAccount(merchantLogin).SetOrderReference("AAA"))
+	require.NoError(t, err)
+	require.Equal(t, []string{wfp.DefaultBaseURL, wfp.DefaultBaseURL}, urls)
+
+	_, err = wfp.Do[*wfp.SettleRequest, wfp.SettleResponse](ctx, wfpClient, wfpClient.NewSettleRequest())
+	require.ErrorIs(t, err, wfp.ErrOrderReferenceRequired)
+	require.Len(t, urls, 2)
+}
+
+func TestWayForPay_TransportErrors(t *testing.T) {
+	ctx := context.Background()
+	maintenance := "<html><body>" + strings.Repeat("Технічні роботи. ", 100) + "</body></html>"
+
+	t.Run("emulator", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		t.Cleanup(srv.Close)
+		wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret)
+		require.NoError(t, err)
+
+		cases := []struct {
+			name        string
+			statusCode  int
+			contentType string
+			body        string
+			want        error
+			retryable   bool
+		}{
+			{"maintenance page", http.StatusServiceUnavailable, "text/html", maintenance, wfp.ErrUnexpectedStatus, true},
+			{"html with 200", http.StatusOK, "text/html", maintenance, wfp.ErrNonJSONResponse, false},
+			{"client error", http.StatusBadRequest, "application/json", `{"reason":"bad"}`, wfp.ErrUnexpectedStatus, false},
+			{"rate limited", http.StatusTooManyRequests, "text/plain", "slow down", wfp.ErrUnexpectedStatus, true},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				srv.RespondNext("CHECK_STATUS", tc.statusCode, tc.contentType, tc.body)
+				_, err := wfpClient.CheckStatus(ctx, "ORDER-1")
+				require.ErrorIs(t, err, tc.want)
+
+				var transportErr *wfp.TransportError
+				require.ErrorAs(t, err, &transportErr)
+				require.Equal(t, tc.statusCode, transportErr.StatusCode)
+				require.Equal(t, tc.contentType, transportErr.ContentType)
+				require.LessOrEqual(t, len(transportErr.Body), wfp.MaxErrorBodySnippet)
+				require.True(t, strings.HasPrefix(tc.body, transportErr.Body))
+				require.Equal(t, "CHECK_STATUS", transportErr.TransactionType)
+				require.Equal(t, "ORDER-1", transportErr.OrderReference)
+				require.Equal(t, tc.retryable, transportErr.Retryable())
+			})
+		}
+	})
+
+	t.Run("body limits", func(t *testing.T) {
+		answer := func(body string, contentLength int64) *http.Client {
+			return &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode:    http.StatusOK,
+						Header:        http.Header{"Content-Type": {"application/json"}},
+						Body:          io.NopCloser(strings.NewReader(body)),
+						ContentLength: contentLength,
+						Request:       req,
+					}, nil
+				}),
+			}
+		}
+		body := `{"reasonCode":1100,"reason":"Ok","orderReference":"` + strings.Repeat("A", 100) + `"}`
+
+		wfpClient, err := wfp.NewClient(answer(body, -1), merchantLogin, merchantSecret, wfp.WithMaxResponseSize(64))
+		require.NoError(t, err)
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.ErrorIs(t, err, wfp.ErrResponseTooLarge)
+
+		wfpClient, err = wfp.NewClient(answer(body[:40], int64(len(body))), merchantLogin, merchantSecret)
+		require.NoError(t, err)
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.ErrorIs(t, err, wfp.ErrTruncatedResponse)
+		var transportErr *wfp.TransportError
+		require.ErrorAs(t, err, &transportErr)
+		require.True(t, transportErr.Retryable())
+		require.Equal(t, body[:40], transportErr.Body)
+
+		wfpClient, err = wfp.NewClient(answer(body, -1), merchantLogin, merchantSecret)
+		require.NoError(t, err)
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.NoError(t, err)
+	})
+
+	t.Run("retried on 5xx", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		t.Cleanup(srv.Close)
+		wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret,
+			wfp.WithRetryPolicy(wfp.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+		require.NoError(t, err)
+
+		srv.RespondNext("CHECK_STATUS", http.StatusBadGateway, "text/html", maintenance)
+		_, err = wfpClient.CheckStatus(ctx, "ORDER-1")
+		require.ErrorIs(t, err, wfp.ErrOrderNotFound)
+		require.Equal(t, 2, srv.Calls("CHECK_STATUS"))
+	})
+}
+
+// regularInfo is a custom transaction of the regular payments API.
+type regularInfo struct{ clientInfo }
+
+func (regularInfo) API() wfp.API { return wfp.APIRegular }
+
+// sandboxInfo is a custom transaction posted to an absolute url.
+type sandboxInfo struct{ clientInfo }
+
+func (sandboxInfo) Endpoint() string { return "https://sandbox.example.com/api" }
+
+func TestWayForPay_Endpoint(t *testing.T) {
+	ctx := context.Background()
+	info := clientInfo{account: merchantLogin, phone: "380501234567"}
+	cases := []struct {
+		name string
+		opts []wfp.Option
+		call func(wfpClient *wfp.WayForPay) error
+		want string
+	}{
+		{
+			name: "main api",
+			call: func(wfpClient *wfp.WayForPay) error {
+				_, err := wfpClient.CheckStatus(ctx, "AAA")
+				return err
+			},
+			want: wfp.DefaultBaseURL,
+		},
+		{
+			name: "regular api",
+			opts: []wfp.Option{wfp.WithMerchantPassword("password")},
+			call: func(wfpClient *wfp.WayForPay) error {
+				_, err := wfpClient.RegularPayments().Status(ctx, "AAA")
+				return err
+			},
+			want: wfp.DefaultRegularURL,
+		},
+		{
+			name: "regular api with its own url",
+			opts: []wfp.Option{wfp.WithMerchantPassword("password"), wfp.WithRegularURL("https://proxy.example.com/regular/")},
+			call: func(wfpClient *wfp.WayForPay) error {
+				_, err := wfpClient.RegularPayments().Status(ctx, "AAA")
+				return err
+			},
+			want: "https://proxy.example.com/regular",
+		},
+		{
+			name: "custom transaction",
+			call: func(wfpClient *wfp.WayForPay) error {
+				return wfpClient.Execute(ctx, info, &clientInfoResponse{})
+			},
+			want: wfp.DefaultBaseURL,
+		},
+		{
+			name: "custom transaction of the regular api",
+			call: func(wfpClient *wfp.WayForPay) error {
+				return wfpClient.Execute(ctx, regularInfo{info}, &clientInfoResponse{})
+			},
+			want: wfp.DefaultRegularURL,
+		},
+		{
+			name: "custom transaction with an absolute url",
+			call: func(wfpClient *wfp.WayForPay) error {
+				return wfpClient.Execute(ctx, sandboxInfo{info}, &clientInfoResponse{})
+			},
+			want: "https://sandbox.example.com/api",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			acquirer := newStub(func(stubRequest) string { return `{"reasonCode":1100,"reason":"Ok"}` })
+			wfpClient, err := wfp.NewClient(acquirer.Client(), merchantLogin, merchantSecret, tt.opts...)
+			require.NoError(t, err)
+
+			_ = tt.call(wfpClient)
+			require.Equal(t, []string{tt.want}, acquirer.urls())
+		})
+	}
+}
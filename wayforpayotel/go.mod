@@ -0,0 +1,27 @@
+module github.com/fairytale5571/wayforpay/wayforpayotel
+
+go 1.23.0
+
+require (
+	github.com/fairytale5571/wayforpay v0.0.0-20261018083436-adacde2789f7
+	github.com/stretchr/testify v1.11.1
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/sdk v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The replace only serves development inside this repository; it is ignored
+// when the module is required from elsewhere, which uses the version above.
+replace github.com/fairytale5571/wayforpay => ../
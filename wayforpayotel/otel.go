@@ -0,0 +1,58 @@
+// Package wayforpayotel reports WayForPay calls as OpenTelemetry spans.
+//
+//	client, _ := wayforpay.NewClient(nil, login, secret,
+//		wayforpay.WithObserver(wayforpayotel.NewObserver(otel.Tracer("payments"))))
+//
+// It is a separate module so that the SDK does not depend on OpenTelemetry.
+package wayforpayotel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fairytale5571/wayforpay"
+)
+
+// NewObserver returns a wayforpay.TracingObserver that starts a client span
+// with tracer for every call.
+func NewObserver(tracer trace.Tracer) *wayforpay.TracingObserver {
+	return &wayforpay.TracingObserver{Tracer: Tracer{Tracer: tracer}}
+}
+
+// Tracer adapts a trace.Tracer to wayforpay.SpanTracer.
+type Tracer struct {
+	Tracer trace.Tracer
+}
+
+func (t Tracer) StartSpan(ctx context.Context, name string) (context.Context, wayforpay.Span) {
+	ctx, s := t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, span{s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetAttribute(key string, value any) {
+	switch v := value.(type) {
+	case string:
+		s.span.SetAttributes(attribute.String(key, v))
+	case int:
+		s.span.SetAttributes(attribute.Int(key, v))
+	default:
+		s.span.SetAttributes(attribute.String(key, fmt.Sprint(v)))
+	}
+}
+
+func (s span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.span.End()
+}
@@ -0,0 +1,96 @@
+package wayforpayotel_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpayotel"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+)
+
+const (
+	merchantLogin  = "test_merch_n1"
+	merchantSecret = "flk3409refn54t54t*FNJRET"
+)
+
+func TestObserver(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	srv.AddOrder(wayforpaytest.Order{OrderReference: "PAID", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved, ReasonCode: wayforpaytest.ReasonOk})
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	tracer := provider.Tracer("wayforpay")
+
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret,
+		wfp.WithObserver(wayforpayotel.NewObserver(tracer)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		orderReference string
+		wantAttributes []attribute.KeyValue
+		wantStatus     codes.Code
+		wantErr        error
+	}{
+		{
+			name:           "approved",
+			orderReference: "PAID",
+			wantAttributes: []attribute.KeyValue{
+				attribute.String(wfp.AttrTransactionType, "CHECK_STATUS"),
+				attribute.String(wfp.AttrOrderReference, "PAID"),
+				attribute.Int(wfp.AttrHTTPStatus, http.StatusOK),
+				attribute.Int(wfp.AttrReasonCode, wayforpaytest.ReasonOk),
+				attribute.Int(wfp.AttrAttempts, 1),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:           "declined",
+			orderReference: "UNKNOWN",
+			wantAttributes: []attribute.KeyValue{
+				attribute.String(wfp.AttrTransactionType, "CHECK_STATUS"),
+				attribute.String(wfp.AttrOrderReference, "UNKNOWN"),
+				attribute.Int(wfp.AttrHTTPStatus, http.StatusOK),
+				attribute.Int(wfp.AttrReasonCode, wayforpaytest.ReasonOrderNotFound),
+				attribute.Int(wfp.AttrAttempts, 1),
+			},
+			wantStatus: codes.Error,
+			wantErr:    wfp.ErrOrderNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, parent := tracer.Start(context.Background(), "checkout")
+			_, err := wfpClient.CheckStatus(ctx, tt.orderReference)
+			parent.End()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			ended := recorder.Ended()
+			span := ended[len(ended)-2]
+			require.Equal(t, "wayforpay CHECK_STATUS", span.Name())
+			require.Equal(t, trace.SpanKindClient, span.SpanKind())
+			require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			require.ElementsMatch(t, tt.wantAttributes, span.Attributes())
+			require.Equal(t, tt.wantStatus, span.Status().Code)
+			if tt.wantErr != nil {
+				require.Len(t, span.Events(), 1)
+				require.Equal(t, "exception", span.Events()[0].Name)
+			}
+		})
+	}
+}
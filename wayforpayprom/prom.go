@@ -0,0 +1,84 @@
+// Package wayforpayprom exports WayForPay call metrics to Prometheus.
+//
+//	observer := wayforpayprom.NewObserver()
+//	prometheus.MustRegister(observer)
+//	client, _ := wayforpay.NewClient(nil, login, secret, wayforpay.WithObserver(observer))
+//
+// It is a separate module so that the SDK does not depend on client_golang.
+package wayforpayprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fairytale5571/wayforpay"
+)
+
+// Metric names exported by Observer.
+const (
+	LatencyName = "wayforpay_call_duration_seconds"
+	CallsName   = "wayforpay_calls_total"
+	RetriesName = "wayforpay_retries_total"
+)
+
+// Observer is a wayforpay.PrometheusObserver backed by Prometheus collectors.
+// It is itself a prometheus.Collector and has to be registered to be exported.
+type Observer struct {
+	wayforpay.PrometheusObserver
+
+	latency *prometheus.HistogramVec
+	calls   *prometheus.CounterVec
+	retries *prometheus.CounterVec
+}
+
+// NewObserver returns an Observer with the default histogram buckets.
+func NewObserver() *Observer {
+	o := &Observer{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    LatencyName,
+			Help:    "Duration of WayForPay calls in seconds, retries and backoff included.",
+			Buckets: prometheus.DefBuckets,
+		}, wayforpay.LatencyLabels),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: CallsName,
+			Help: "WayForPay calls by HTTP status and reason code.",
+		}, wayforpay.CallLabels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: RetriesName,
+			Help: "WayForPay attempts after the first one.",
+		}, wayforpay.RetryLabels),
+	}
+	o.PrometheusObserver = wayforpay.PrometheusObserver{
+		Latency: histogram{o.latency},
+		Calls:   counter{o.calls},
+		Retries: counter{o.retries},
+	}
+	return o
+}
+
+func (o *Observer) Describe(ch chan<- *prometheus.Desc) {
+	o.latency.Describe(ch)
+	o.calls.Describe(ch)
+	o.retries.Describe(ch)
+}
+
+func (o *Observer) Collect(ch chan<- prometheus.Metric) {
+	o.latency.Collect(ch)
+	o.calls.Collect(ch)
+	o.retries.Collect(ch)
+}
+
+type histogram struct {
+	vec *prometheus.HistogramVec
+}
+
+func (h histogram) Observe(value float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+}
+
+type counter struct {
+	vec *prometheus.CounterVec
+}
+
+func (c counter) Add(value float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(value)
+}
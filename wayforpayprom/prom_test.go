@@ -0,0 +1,106 @@
+package wayforpayprom_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	wfp "github.com/fairytale5571/wayforpay"
+	"github.com/fairytale5571/wayforpay/wayforpayprom"
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+)
+
+const (
+	merchantLogin  = "test_merch_n1"
+	merchantSecret = "flk3409refn54t54t*FNJRET"
+)
+
+func TestObserver(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	t.Cleanup(srv.Close)
+	srv.AddOrder(wayforpaytest.Order{OrderReference: "PAID", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved, ReasonCode: wayforpaytest.ReasonOk})
+
+	observer := wayforpayprom.NewObserver()
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(observer))
+
+	wfpClient, err := wfp.NewClient(srv.Client(), merchantLogin, merchantSecret,
+		wfp.WithRetryPolicy(wfp.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		wfp.WithObserver(observer))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = wfpClient.CheckStatus(ctx, "PAID")
+	require.NoError(t, err)
+	srv.FailNext("CHECK_STATUS", 1131, "In processing")
+	_, err = wfpClient.CheckStatus(ctx, "UNKNOWN")
+	require.ErrorIs(t, err, wfp.ErrOrderNotFound)
+	srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html></html>")
+	srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html></html>")
+	srv.RespondNext("CHECK_STATUS", http.StatusServiceUnavailable, "text/html", "<html></html>")
+	_, err = wfpClient.CheckStatus(ctx, "PAID")
+	require.ErrorIs(t, err, wfp.ErrUnexpectedStatus)
+
+	tests := []struct {
+		name   string
+		metric string
+		want   string
+	}{
+		{
+			name:   "calls",
+			metric: wayforpayprom.CallsName,
+			want: `
+# HELP wayforpay_calls_total WayForPay calls by HTTP status and reason code.
+# TYPE wayforpay_calls_total counter
+wayforpay_calls_total{http_status="200",reason_code="1100",transaction_type="CHECK_STATUS"} 1
+wayforpay_calls_total{http_status="200",reason_code="1127",transaction_type="CHECK_STATUS"} 1
+wayforpay_calls_total{http_status="503",reason_code="0",transaction_type="CHECK_STATUS"} 1
+`,
+		},
+		{
+			name:   "retries",
+			metric: wayforpayprom.RetriesName,
+			want: `
+# HELP wayforpay_retries_total WayForPay attempts after the first one.
+# TYPE wayforpay_retries_total counter
+wayforpay_retries_total{transaction_type="CHECK_STATUS"} 3
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(tt.want), tt.metric))
+		})
+	}
+
+	t.Run("latency", func(t *testing.T) {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		counts := map[string]uint64{}
+		for _, family := range families {
+			if family.GetName() != wayforpayprom.LatencyName {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				var outcome string
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "outcome" {
+						outcome = label.GetValue()
+					}
+				}
+				counts[outcome] += m.GetHistogram().GetSampleCount()
+			}
+		}
+		require.Equal(t, map[string]uint64{
+			wfp.OutcomeSuccess:   1,
+			wfp.OutcomeDeclined:  1,
+			wfp.OutcomeTransport: 1,
+		}, counts)
+	})
+}
@@ -0,0 +1,817 @@
+// Package wayforpaytest provides an in-memory WayForPay API emulator for tests.
+//
+// The server verifies merchantSignature with the same HMAC-MD5 rules the SDK
+// uses, keeps the state of every order in memory and lets tests script
+// declines and arbitrary reason codes:
+//
+//	srv := wayforpaytest.NewServer("test_merch_n1", "secret")
+//	defer srv.Close()
+//	client, _ := wayforpay.NewClient(nil, "test_merch_n1", "secret", wayforpay.WithBaseURL(srv.APIURL))
+//
+// Clients that cannot change their base URL can use Server.Client instead.
+package wayforpaytest
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Order statuses reported by the emulator.
+const (
+	StatusInProcessing        = "InProcessing"
+	StatusWaitingAuthComplete = "WaitingAuthComplete"
+	StatusApproved            = "Approved"
+	StatusDeclined            = "Declined"
+	StatusRefunded            = "Refunded"
+	StatusRefundInProcessing  = "RefundInProcessing"
+	StatusVoided              = "Voided"
+	StatusExpired             = "Expired"
+	StatusRemoved             = "Removed"
+)
+
+// Reason codes used by the emulator.
+const (
+	ReasonOk                 = 1100
+	ReasonThreeDSFail        = 1108
+	ReasonFormatError        = 1109
+	ReasonInvalidCurrency    = 1110
+	ReasonDuplicateOrder     = 1112
+	ReasonInvalidSignature   = 1113
+	ReasonParameterMissing   = 1115
+	ReasonAccountNotFound    = 1121
+	ReasonRefundNotAllowed   = 1123
+	ReasonIllegalOrderState  = 1126
+	ReasonOrderNotFound      = 1127
+	ReasonRefundLimit        = 1128
+	ReasonInvalidAmount      = 1130
+	ReasonRefundInProcessing = 1138
+)
+
+// MaxTransactionListWindow is the longest period accepted by TRANSACTION_LIST.
+const MaxTransactionListWindow = 31 * 24 * time.Hour
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Order is the emulator's view of a merchant order.
+type Order struct {
+	OrderReference          string
+	MerchantTransactionType string
+	Amount                  float64
+	Currency                string
+	RefundedAmount          float64
+	Status                  string
+	ReasonCode              int
+	Reason                  string
+	CreatedDate             time.Time
+	ProcessingDate          time.Time
+	// D3Md is the 3-D Secure MD of an InProcessing charge, the value
+	// COMPLETE_3DS must present to approve the order.
+	D3Md string
+	// PendingRefund is the amount of a RefundInProcessing refund, added to
+	// RefundedAmount by FinishRefund.
+	PendingRefund float64
+}
+
+// Transaction is a single entry reported by TRANSACTION_LIST.
+type Transaction struct {
+	TransactionType   string
+	OrderReference    string
+	Amount            float64
+	Currency          string
+	TransactionStatus string
+	ReasonCode        int
+	Reason            string
+	CreatedDate       time.Time
+	ProcessingDate    time.Time
+}
+
+type outcome struct {
+	reasonCode int
+	reason     string
+	// apply makes the request take effect before it is answered with reasonCode.
+	apply bool
+}
+
+type rawAnswer struct {
+	statusCode  int
+	contentType string
+	body        string
+}
+
+// APIPath is the only path the emulator answers on, the path of DefaultBaseURL.
+// Requests to any other path get 404 Not Found, as from the real API.
+const APIPath = "/api"
+
+// Server emulates the WayForPay API on top of httptest.Server.
+type Server struct {
+	// URL is the root URL of the emulator, e.g. http://127.0.0.1:1234.
+	URL string
+	// APIURL is URL followed by APIPath, the value to pass to WithBaseURL.
+	APIURL string
+
+	srv            *httptest.Server
+	merchantLogin  string
+	merchantSecret string
+	ticket         string
+
+	mu           sync.Mutex
+	now          func() time.Time
+	orders       map[string]*Order
+	transactions []Transaction
+	scripted     map[string][]outcome
+	dropped      map[string]int
+	held         int
+	raw          map[string][]rawAnswer
+	calls        map[string]int
+	rates        map[string]float64
+}
+
+// NewServer starts an emulator that accepts requests signed with merchantSecret
+// on behalf of merchantLogin. Call Close when finished.
+func NewServer(merchantLogin, merchantSecret string) *Server {
+	s := &Server{
+		merchantLogin:  merchantLogin,
+		merchantSecret: merchantSecret,
+		now:            time.Now,
+		orders:         map[string]*Order{},
+		scripted:       map[string][]outcome{},
+		dropped:        map[string]int{},
+		raw:            map[string][]rawAnswer{},
+		calls:          map[string]int{},
+		rates:          map[string]float64{"USD": 41.25, "EUR": 44.8},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	s.APIURL = s.srv.URL + APIPath
+	return s
+}
+
+// Close shuts the emulator down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns an *http.Client that delivers every request to the emulator,
+// whatever host the request was addressed to. The path is kept, so a client
+// with a wrong base URL still gets 404 Not Found.
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{
+		Transport: &rewriteTransport{target: target, base: s.srv.Client().Transport},
+	}
+}
+
+// SetAuthorizationTicket sets the merchant authorization ticket that COMPLETE_3DS
+// requests must present. Until it is set every completion is rejected.
+func (s *Server) SetAuthorizationTicket(ticket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ticket = ticket
+}
+
+// SetClock overrides the time source used for order and transaction dates.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetRates replaces the exchange rates returned by CURRENCY_RATES.
+func (s *Server) SetRates(rates map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates = rates
+}
+
+// FailNext makes the next request of transactionType answer with reasonCode
+// and reason without touching the order state. Calls are queued.
+func (s *Server) FailNext(transactionType string, reasonCode int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripted[transactionType] = append(s.scripted[transactionType], outcome{reasonCode: reasonCode, reason: reason})
+}
+
+// ProcessNext makes the next request of transactionType take effect but answer
+// with reasonCode and reason, as a request WayForPay accepted and is still
+// processing, e.g. 1138 Refund in processing. A request that fails on its own
+// gets its own answer. Calls are queued together with FailNext.
+func (s *Server) ProcessNext(transactionType string, reasonCode int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripted[transactionType] = append(s.scripted[transactionType], outcome{reasonCode: reasonCode, reason: reason, apply: true})
+}
+
+// DropNextResponse makes the next request of transactionType take effect but
+// closes the connection instead of answering, as if the answer was lost on the
+// way back. Calls are queued.
+func (s *Server) DropNextResponse(transactionType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropped[transactionType]++
+}
+
+// HoldNextRefund makes the next REFUND of an approved order stay in
+// processing: the order becomes RefundInProcessing with its refunded amount
+// unchanged until FinishRefund, and the refund is answered with 1138 Refund in
+// processing. Calls are queued.
+func (s *Server) HoldNextRefund() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held++
+}
+
+// RespondNext makes the next request of transactionType answer with the given
+// HTTP status, content type and body, e.g. an HTML maintenance page, without
+// touching the order state. Calls are queued.
+func (s *Server) RespondNext(transactionType string, statusCode int, contentType, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw[transactionType] = append(s.raw[transactionType], rawAnswer{statusCode: statusCode, contentType: contentType, body: body})
+}
+
+// AddOrder seeds an order, e.g. one that was paid outside of the test.
+func (s *Server) AddOrder(order Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if order.Status == "" {
+		order.Status = StatusInProcessing
+	}
+	if order.CreatedDate.IsZero() {
+		order.CreatedDate = s.now()
+	}
+	o := order
+	s.orders[order.OrderReference] = &o
+}
+
+// AddTransaction seeds an entry reported by TRANSACTION_LIST.
+func (s *Server) AddTransaction(transaction Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactions = append(s.transactions, transaction)
+}
+
+// Order returns a copy of the order state.
+func (s *Server) Order(orderReference string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderReference]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// Calls returns how many requests of transactionType the emulator received.
+func (s *Server) Calls(transactionType string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[transactionType]
+}
+
+// Approve simulates a successful customer payment. Orders created with
+// merchantTransactionType AUTH are put on hold until SETTLE.
+func (s *Server) Approve(orderReference string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderReference]
+	if !ok {
+		return fmt.Errorf("wayforpaytest: order %q not found", orderReference)
+	}
+	if o.Status != StatusInProcessing {
+		return fmt.Errorf("wayforpaytest: order %q is %s", orderReference, o.Status)
+	}
+	s.approve(o)
+	return nil
+}
+
+func (s *Server) approve(o *Order) {
+	o.Status = StatusApproved
+	transactionType := "SALE"
+	if o.MerchantTransactionType == "AUTH" {
+		o.Status = StatusWaitingAuthComplete
+		transactionType = "AUTH"
+	}
+	o.ReasonCode, o.Reason = ReasonOk, "Ok"
+	o.ProcessingDate = s.now()
+	s.record(o, transactionType, o.Amount)
+}
+
+// FinishRefund completes the refund held by HoldNextRefund.
+func (s *Server) FinishRefund(orderReference string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderReference]
+	if !ok {
+		return fmt.Errorf("wayforpaytest: order %q not found", orderReference)
+	}
+	if o.Status != StatusRefundInProcessing {
+		return fmt.Errorf("wayforpaytest: order %q is %s", orderReference, o.Status)
+	}
+	o.RefundedAmount, o.PendingRefund = round(o.RefundedAmount+o.PendingRefund), 0
+	o.Status = StatusApproved
+	if o.RefundedAmount == o.Amount {
+		o.Status = StatusRefunded
+	}
+	o.ProcessingDate = s.now()
+	return nil
+}
+
+// Decline simulates a failed customer payment with the given reason code.
+func (s *Server) Decline(orderReference string, reasonCode int, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderReference]
+	if !ok {
+		return fmt.Errorf("wayforpaytest: order %q not found", orderReference)
+	}
+	o.Status = StatusDeclined
+	o.ReasonCode, o.Reason = reasonCode, reason
+	o.ProcessingDate = s.now()
+	s.record(o, "SALE", o.Amount)
+	return nil
+}
+
+func (s *Server) record(o *Order, transactionType string, amount float64) {
+	s.transactions = append(s.transactions, Transaction{
+		TransactionType:   transactionType,
+		OrderReference:    o.OrderReference,
+		Amount:            amount,
+		Currency:          o.Currency,
+		TransactionStatus: o.Status,
+		ReasonCode:        o.ReasonCode,
+		Reason:            o.Reason,
+		CreatedDate:       o.CreatedDate,
+		ProcessingDate:    o.ProcessingDate,
+	})
+}
+
+// signatureFields lists the request fields signed for every transaction type, in order.
+var signatureFields = map[string][]string{
+	"CREATE_INVOICE":   {"merchantAccount", "merchantDomainName", "orderReference", "orderDate", "amount", "currency", "productName", "productCount", "productPrice"},
+	"REMOVE_INVOICE":   {"merchantAccount", "orderReference"},
+	"CHECK_STATUS":     {"merchantAccount", "orderReference"},
+	"REFUND":           {"merchantAccount", "orderReference", "amount", "currency"},
+	"SETTLE":           {"merchantAccount", "orderReference", "amount", "currency"},
+	"TRANSACTION_LIST": {"merchantAccount", "dateBegin", "dateEnd"},
+	"CURRENCY_RATES":   {"merchantAccount", "orderDate"},
+}
+
+// ticketFields lists the required fields of transaction types that are
+// authorized by the merchant authorization ticket instead of a signature.
+var ticketFields = map[string][]string{
+	"COMPLETE_3DS": {"authorization_ticket", "d3Md", "d3Pares"},
+}
+
+type request map[string]any
+
+// strings returns the textual representation of a field the way it is signed.
+func (r request) strings(field string) []string {
+	switch v := r[field].(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case json.Number:
+		return []string{v.String()}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, request{"item": item}.strings("item")...)
+		}
+		return out
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func (r request) string(field string) string {
+	return strings.Join(r.strings(field), ";")
+}
+
+func (r request) amount(field string) (float64, bool) {
+	v, err := strconv.ParseFloat(r.string(field), 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return round(v), true
+}
+
+func (r request) unix(field string) (time.Time, bool) {
+	v, err := strconv.ParseInt(r.string(field), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(v, 0), true
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func sign(secret string, fields []string) string {
+	h := hmac.New(md5.New, []byte(secret))
+	h.Write([]byte(strings.Join(fields, ";")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != APIPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	var req request
+	if err := dec.Decode(&req); err != nil {
+		writeJSON(w, failure(ReasonFormatError, "Format Error"))
+		return
+	}
+	if answer, ok := s.rawAnswer(req.string("transactionType")); ok {
+		w.Header().Set("Content-Type", answer.contentType)
+		w.WriteHeader(answer.statusCode)
+		_, _ = io.WriteString(w, answer.body)
+		return
+	}
+	resp := s.handle(req)
+	if s.drop(req.string("transactionType")) {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	writeJSON(w, resp)
+}
+
+// rawAnswer pops the next answer queued by RespondNext for transactionType.
+func (s *Server) rawAnswer(transactionType string) (rawAnswer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue := s.raw[transactionType]
+	if len(queue) == 0 {
+		return rawAnswer{}, false
+	}
+	s.raw[transactionType] = queue[1:]
+	s.calls[transactionType]++
+	return queue[0], true
+}
+
+// drop reports whether the answer to a request of transactionType is to be lost.
+func (s *Server) drop(transactionType string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dropped[transactionType] == 0 {
+		return false
+	}
+	s.dropped[transactionType]--
+	return true
+}
+
+func (s *Server) handle(req request) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transactionType := req.string("transactionType")
+	s.calls[transactionType]++
+
+	if resp := s.authenticate(req); resp != nil {
+		return resp
+	}
+	if queue := s.scripted[transactionType]; len(queue) > 0 {
+		s.scripted[transactionType] = queue[1:]
+		if queue[0].apply {
+			if resp := s.dispatch(req); resp["reasonCode"] != ReasonOk {
+				return resp
+			}
+		}
+		resp := failure(queue[0].reasonCode, queue[0].reason)
+		resp["orderReference"] = req.string("orderReference")
+		return resp
+	}
+	return s.dispatch(req)
+}
+
+// authenticate returns the failure for a request that is not authorized by
+// the signature or the ticket its transaction type requires, nil otherwise.
+func (s *Server) authenticate(req request) map[string]any {
+	transactionType := req.string("transactionType")
+	if fields, ok := ticketFields[transactionType]; ok {
+		if resp := missing(req, fields); resp != nil {
+			return resp
+		}
+		if s.ticket == "" || !hmac.Equal([]byte(s.ticket), []byte(req.string("authorization_ticket"))) {
+			return failure(ReasonInvalidSignature, "Invalid signature")
+		}
+		return nil
+	}
+	fields, ok := signatureFields[transactionType]
+	if !ok {
+		return failure(ReasonFormatError, "Format Error")
+	}
+	if resp := missing(req, fields); resp != nil {
+		return resp
+	}
+	if req.string("merchantAccount") != s.merchantLogin {
+		return failure(ReasonAccountNotFound, "Account Not Found")
+	}
+	data := make([]string, 0, len(fields))
+	for _, field := range fields {
+		data = append(data, req.strings(field)...)
+	}
+	if !hmac.Equal([]byte(sign(s.merchantSecret, data)), []byte(req.string("merchantSignature"))) {
+		return failure(ReasonInvalidSignature, "Invalid signature")
+	}
+	return nil
+}
+
+// missing returns the failure for the first of fields absent from req.
+func missing(req request, fields []string) map[string]any {
+	for _, field := range fields {
+		if _, ok := req[field]; !ok {
+			return failure(ReasonParameterMissing, fmt.Sprintf("Parameter `%s` is missing", field))
+		}
+	}
+	return nil
+}
+
+func (s *Server) dispatch(req request) map[string]any {
+	switch req.string("transactionType") {
+	case "CREATE_INVOICE":
+		return s.createInvoice(req)
+	case "REMOVE_INVOICE":
+		return s.removeInvoice(req)
+	case "CHECK_STATUS":
+		return s.checkStatus(req)
+	case "REFUND":
+		return s.refund(req)
+	case "SETTLE":
+		return s.settle(req)
+	case "COMPLETE_3DS":
+		return s.complete3DS(req)
+	case "CURRENCY_RATES":
+		return map[string]any{
+			"reason":     "Ok",
+			"reasonCode": ReasonOk,
+			"rates":      s.rates,
+		}
+	default:
+		return s.transactionList(req)
+	}
+}
+
+func (s *Server) createInvoice(req request) map[string]any {
+	orderReference := req.string("orderReference")
+	if _, ok := s.orders[orderReference]; ok {
+		return failure(ReasonDuplicateOrder, "Duplicate Order ID")
+	}
+	amount, ok := req.amount("amount")
+	if !ok {
+		return failure(ReasonInvalidAmount, "Invalid Amount")
+	}
+	currency := req.string("currency")
+	if !currencyPattern.MatchString(currency) {
+		return failure(ReasonInvalidCurrency, "Invalid Currency")
+	}
+	s.orders[orderReference] = &Order{
+		OrderReference:          orderReference,
+		MerchantTransactionType: req.string("merchantTransactionType"),
+		Amount:                  amount,
+		Currency:                currency,
+		Status:                  StatusInProcessing,
+		ReasonCode:              ReasonOk,
+		Reason:                  "Ok",
+		CreatedDate:             s.now(),
+	}
+	invoiceURL := s.URL + "/invoice/" + url.PathEscape(orderReference)
+	return map[string]any{
+		"reason":     "Ok",
+		"reasonCode": ReasonOk,
+		"invoiceUrl": invoiceURL,
+		"qrCode":     invoiceURL + "/qr",
+	}
+}
+
+func (s *Server) removeInvoice(req request) map[string]any {
+	o, ok := s.orders[req.string("orderReference")]
+	if !ok {
+		return failure(ReasonOrderNotFound, "Order Not Found")
+	}
+	if o.Status != StatusInProcessing {
+		return failure(ReasonIllegalOrderState, "Illegal Order State")
+	}
+	o.Status = StatusRemoved
+	return failure(ReasonOk, "Ok")
+}
+
+func (s *Server) checkStatus(req request) map[string]any {
+	o, ok := s.orders[req.string("orderReference")]
+	if !ok {
+		resp := failure(ReasonOrderNotFound, "Order Not Found")
+		resp["orderReference"] = req.string("orderReference")
+		return resp
+	}
+	resp := map[string]any{
+		"merchantAccount":   s.merchantLogin,
+		"orderReference":    o.OrderReference,
+		"amount":            o.Amount,
+		"currency":          o.Currency,
+		"createdDate":       o.CreatedDate.Unix(),
+		"transactionStatus": o.Status,
+		"reason":            o.Reason,
+		"reasonCode":        o.ReasonCode,
+		"refundAmount":      o.RefundedAmount,
+	}
+	if !o.ProcessingDate.IsZero() {
+		resp["processingDate"] = o.ProcessingDate.Unix()
+	}
+	resp["merchantSignature"] = sign(s.merchantSecret, []string{
+		s.merchantLogin,
+		o.OrderReference,
+		strconv.FormatFloat(o.Amount, 'f', -1, 64),
+		o.Currency,
+		o.Status,
+		strconv.Itoa(o.ReasonCode),
+	})
+	return resp
+}
+
+func (s *Server) refund(req request) map[string]any {
+	o, ok := s.orders[req.string("orderReference")]
+	if !ok {
+		return failure(ReasonOrderNotFound, "Order Not Found")
+	}
+	amount, ok := req.amount("amount")
+	if !ok {
+		return failure(ReasonInvalidAmount, "Invalid Amount")
+	}
+	if req.string("currency") != o.Currency {
+		return failure(ReasonInvalidCurrency, "Invalid Currency")
+	}
+	switch o.Status {
+	case StatusApproved:
+		if round(o.RefundedAmount+amount) > o.Amount {
+			return failure(ReasonRefundLimit, "Refund Limit Excended")
+		}
+		if s.held > 0 {
+			s.held--
+			o.Status, o.PendingRefund = StatusRefundInProcessing, amount
+			o.ProcessingDate = s.now()
+			s.record(o, "REFUND", amount)
+			resp := failure(ReasonRefundInProcessing, "Refund in processing")
+			resp["orderReference"] = o.OrderReference
+			resp["transactionStatus"] = o.Status
+			return resp
+		}
+		o.RefundedAmount = round(o.RefundedAmount + amount)
+		if o.RefundedAmount == o.Amount {
+			o.Status = StatusRefunded
+		}
+	case StatusRefundInProcessing:
+		return failure(ReasonRefundInProcessing, "Refund in processing")
+	case StatusWaitingAuthComplete:
+		o.Status = StatusVoided
+	default:
+		return failure(ReasonRefundNotAllowed, "Refund Not Allowed")
+	}
+	o.ProcessingDate = s.now()
+	s.record(o, "REFUND", amount)
+	return map[string]any{
+		"merchantAccount":   s.merchantLogin,
+		"orderReference":    o.OrderReference,
+		"transactionStatus": o.Status,
+		"reason":            "Ok",
+		"reasonCode":        ReasonOk,
+	}
+}
+
+func (s *Server) settle(req request) map[string]any {
+	o, ok := s.orders[req.string("orderReference")]
+	if !ok {
+		return failure(ReasonOrderNotFound, "Order Not Found")
+	}
+	if o.Status != StatusWaitingAuthComplete {
+		return failure(ReasonIllegalOrderState, "Illegal Order State")
+	}
+	amount, ok := req.amount("amount")
+	if !ok || amount > o.Amount {
+		return failure(ReasonInvalidAmount, "Invalid Amount")
+	}
+	if req.string("currency") != o.Currency {
+		return failure(ReasonInvalidCurrency, "Invalid Currency")
+	}
+	o.Amount = amount
+	o.Status = StatusApproved
+	o.ProcessingDate = s.now()
+	s.record(o, "SETTLE", amount)
+	return map[string]any{
+		"merchantAccount":   s.merchantLogin,
+		"orderReference":    o.OrderReference,
+		"transactionStatus": o.Status,
+		"reason":            "Ok",
+		"reasonCode":        ReasonOk,
+	}
+}
+
+func (s *Server) complete3DS(req request) map[string]any {
+	var o *Order
+	for _, order := range s.orders {
+		if order.D3Md != "" && order.D3Md == req.string("d3Md") {
+			o = order
+		}
+	}
+	if o == nil || req.string("d3Pares") == "" {
+		return failure(ReasonThreeDSFail, "3DS Fail")
+	}
+	if o.Status != StatusInProcessing {
+		return failure(ReasonIllegalOrderState, "Illegal Order State")
+	}
+	o.D3Md = ""
+	s.approve(o)
+	return map[string]any{
+		"merchantAccount":   s.merchantLogin,
+		"orderReference":    o.OrderReference,
+		"amount":            o.Amount,
+		"currency":          o.Currency,
+		"transactionStatus": o.Status,
+		"reason":            o.Reason,
+		"reasonCode":        o.ReasonCode,
+	}
+}
+
+func (s *Server) transactionList(req request) map[string]any {
+	begin, ok := req.unix("dateBegin")
+	if !ok {
+		return failure(ReasonFormatError, "Format Error")
+	}
+	end, ok := req.unix("dateEnd")
+	if !ok || end.Before(begin) || end.Sub(begin) > MaxTransactionListWindow {
+		return failure(ReasonFormatError, "Format Error")
+	}
+	list := []map[string]any{}
+	for _, t := range s.transactions {
+		if t.ProcessingDate.Before(begin) || t.ProcessingDate.After(end) {
+			continue
+		}
+		list = append(list, map[string]any{
+			"transactionType":   t.TransactionType,
+			"orderReference":    t.OrderReference,
+			"createdDate":       t.CreatedDate.Unix(),
+			"amount":            t.Amount,
+			"currency":          t.Currency,
+			"transactionStatus": t.TransactionStatus,
+			"processingDate":    t.ProcessingDate.Unix(),
+			"reasonCode":        t.ReasonCode,
+			"reason":            t.Reason,
+		})
+	}
+	return map[string]any{
+		"reason":          "Ok",
+		"reasonCode":      ReasonOk,
+		"transactionList": list,
+	}
+}
+
+func failure(reasonCode int, reason string) map[string]any {
+	return map[string]any{
+		"reason":     reason,
+		"reasonCode": reasonCode,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme = t.target.Scheme
+	out.URL.Host = t.target.Host
+	out.Host = t.target.Host
+	return t.base.RoundTrip(out)
+}
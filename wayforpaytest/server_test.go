@@ -0,0 +1,378 @@
+package wayforpaytest_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fairytale5571/wayforpay/wayforpaytest"
+)
+
+const (
+	merchantLogin  = "test_merch_n1"
+	merchantSecret = "flk3409refn54t54t*FNJRET"
+)
+
+// signed returns the request with merchantAccount and a merchantSignature over
+// the values of fields, in order.
+func signed(secret string, req map[string]any, fields ...string) map[string]any {
+	if _, ok := req["merchantAccount"]; !ok {
+		req["merchantAccount"] = merchantLogin
+	}
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		switch v := req[field].(type) {
+		case []string:
+			values = append(values, v...)
+		default:
+			b, _ := json.Marshal(v)
+			values = append(values, strings.Trim(string(b), `"`))
+		}
+	}
+	h := hmac.New(md5.New, []byte(secret))
+	h.Write([]byte(strings.Join(values, ";")))
+	req["merchantSignature"] = hex.EncodeToString(h.Sum(nil))
+	return req
+}
+
+func checkStatus(orderReference string) map[string]any {
+	return signed(merchantSecret, map[string]any{
+		"transactionType": "CHECK_STATUS",
+		"orderReference":  orderReference,
+	}, "merchantAccount", "orderReference")
+}
+
+func refund(orderReference string, amount float64) map[string]any {
+	return signed(merchantSecret, map[string]any{
+		"transactionType": "REFUND",
+		"orderReference":  orderReference,
+		"amount":          amount,
+		"currency":        "UAH",
+	}, "merchantAccount", "orderReference", "amount", "currency")
+}
+
+func post(t *testing.T, url string, req map[string]any) (int, map[string]any) {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var answer map[string]any
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&answer))
+	}
+	return resp.StatusCode, answer
+}
+
+func reasonCode(answer map[string]any) int {
+	code, _ := answer["reasonCode"].(float64)
+	return int(code)
+}
+
+func TestServer_Routing(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{"api path", srv.APIURL, http.StatusOK},
+		{"root", srv.URL, http.StatusNotFound},
+		{"regular api", srv.URL + "/regularApi", http.StatusNotFound},
+		{"doubled path", srv.APIURL + "/api", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := post(t, tt.url, checkStatus("AAA"))
+			require.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func TestServer_Signature(t *testing.T) {
+	srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+	defer srv.Close()
+	srv.SetAuthorizationTicket("ticket")
+	srv.AddOrder(wayforpaytest.Order{OrderReference: "AAA", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved, ReasonCode: wayforpaytest.ReasonOk})
+
+	tests := []struct {
+		name string
+		req  map[string]any
+		want int
+	}{
+		{"valid", checkStatus("AAA"), wayforpaytest.ReasonOk},
+		{
+			"wrong secret",
+			signed("wrong secret", map[string]any{"transactionType": "CHECK_STATUS", "orderReference": "AAA"}, "merchantAccount", "orderReference"),
+			wayforpaytest.ReasonInvalidSignature,
+		},
+		{
+			"unsigned field changed",
+			func() map[string]any { req := checkStatus("BBB"); req["orderReference"] = "AAA"; return req }(),
+			wayforpaytest.ReasonInvalidSignature,
+		},
+		{
+			"missing signed field",
+			signed(merchantSecret, map[string]any{"transactionType": "CHECK_STATUS"}, "merchantAccount"),
+			wayforpaytest.ReasonParameterMissing,
+		},
+		{
+			"wrong merchant",
+			signed(merchantSecret, map[string]any{"transactionType": "CHECK_STATUS", "merchantAccount": "other", "orderReference": "AAA"}, "merchantAccount", "orderReference"),
+			wayforpaytest.ReasonAccountNotFound,
+		},
+		{
+			"completion with the wrong ticket",
+			map[string]any{"transactionType": "COMPLETE_3DS", "authorization_ticket": "other", "d3Md": "md", "d3Pares": "pares"},
+			wayforpaytest.ReasonInvalidSignature,
+		},
+		{
+			"completion without a ticket",
+			map[string]any{"transactionType": "COMPLETE_3DS", "d3Md": "md", "d3Pares": "pares"},
+			wayforpaytest.ReasonParameterMissing,
+		},
+		{
+			"completion with an unknown md",
+			map[string]any{"transactionType": "COMPLETE_3DS", "authorization_ticket": "ticket", "d3Md": "md", "d3Pares": "pares"},
+			wayforpaytest.ReasonThreeDSFail,
+		},
+		{
+			"unknown transaction type",
+			signed(merchantSecret, map[string]any{"transactionType": "UNKNOWN"}, "merchantAccount"),
+			wayforpaytest.ReasonFormatError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, answer := post(t, srv.APIURL, tt.req)
+			require.Equal(t, tt.want, reasonCode(answer))
+		})
+	}
+}
+
+func TestServer_OrderStates(t *testing.T) {
+	createInvoice := func(orderReference, transactionType string) map[string]any {
+		return signed(merchantSecret, map[string]any{
+			"transactionType":         "CREATE_INVOICE",
+			"merchantDomainName":      "www.market.ua",
+			"merchantTransactionType": transactionType,
+			"orderReference":          orderReference,
+			"orderDate":               1421412898,
+			"amount":                  100,
+			"currency":                "UAH",
+			"productName":             []string{"Milk"},
+			"productCount":            []string{"1"},
+			"productPrice":            []string{"100"},
+		}, "merchantAccount", "merchantDomainName", "orderReference", "orderDate", "amount", "currency", "productName", "productCount", "productPrice")
+	}
+	settle := func(orderReference string, amount float64) map[string]any {
+		return signed(merchantSecret, map[string]any{
+			"transactionType": "SETTLE",
+			"orderReference":  orderReference,
+			"amount":          amount,
+			"currency":        "UAH",
+		}, "merchantAccount", "orderReference", "amount", "currency")
+	}
+	removeInvoice := func(orderReference string) map[string]any {
+		return signed(merchantSecret, map[string]any{
+			"transactionType": "REMOVE_INVOICE",
+			"orderReference":  orderReference,
+		}, "merchantAccount", "orderReference")
+	}
+
+	t.Run("sale is refunded in parts", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+
+		_, answer := post(t, srv.APIURL, createInvoice("AAA", "SALE"))
+		require.Equal(t, wayforpaytest.ReasonOk, reasonCode(answer))
+		_, answer = post(t, srv.APIURL, createInvoice("AAA", "SALE"))
+		require.Equal(t, wayforpaytest.ReasonDuplicateOrder, reasonCode(answer))
+
+		_, answer = post(t, srv.APIURL, refund("AAA", 40))
+		require.Equal(t, wayforpaytest.ReasonRefundNotAllowed, reasonCode(answer))
+
+		require.NoError(t, srv.Approve("AAA"))
+		require.Error(t, srv.Approve("AAA"))
+
+		_, answer = post(t, srv.APIURL, refund("AAA", 40))
+		require.Equal(t, wayforpaytest.ReasonOk, reasonCode(answer))
+		require.Equal(t, wayforpaytest.StatusApproved, answer["transactionStatus"])
+		_, answer = post(t, srv.APIURL, refund("AAA", 61))
+		require.Equal(t, wayforpaytest.ReasonRefundLimit, reasonCode(answer))
+		_, answer = post(t, srv.APIURL, refund("AAA", 60))
+		require.Equal(t, wayforpaytest.StatusRefunded, answer["transactionStatus"])
+
+		order, ok := srv.Order("AAA")
+		require.True(t, ok)
+		require.Equal(t, 100.0, order.RefundedAmount)
+	})
+
+	t.Run("auth is settled", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+
+		post(t, srv.APIURL, createInvoice("AAA", "AUTH"))
+		_, answer := post(t, srv.APIURL, settle("AAA", 100))
+		require.Equal(t, wayforpaytest.ReasonIllegalOrderState, reasonCode(answer))
+
+		require.NoError(t, srv.Approve("AAA"))
+		_, answer = post(t, srv.APIURL, checkStatus("AAA"))
+		require.Equal(t, wayforpaytest.StatusWaitingAuthComplete, answer["transactionStatus"])
+
+		_, answer = post(t, srv.APIURL, settle("AAA", 101))
+		require.Equal(t, wayforpaytest.ReasonInvalidAmount, reasonCode(answer))
+		_, answer = post(t, srv.APIURL, settle("AAA", 80))
+		require.Equal(t, wayforpaytest.StatusApproved, answer["transactionStatus"])
+
+		order, _ := srv.Order("AAA")
+		require.Equal(t, 80.0, order.Amount)
+	})
+
+	t.Run("auth is voided by a refund", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+
+		post(t, srv.APIURL, createInvoice("AAA", "AUTH"))
+		require.NoError(t, srv.Approve("AAA"))
+		_, answer := post(t, srv.APIURL, refund("AAA", 100))
+		require.Equal(t, wayforpaytest.StatusVoided, answer["transactionStatus"])
+	})
+
+	t.Run("unpaid invoice is removed", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+
+		_, answer := post(t, srv.APIURL, removeInvoice("AAA"))
+		require.Equal(t, wayforpaytest.ReasonOrderNotFound, reasonCode(answer))
+
+		post(t, srv.APIURL, createInvoice("AAA", "SALE"))
+		_, answer = post(t, srv.APIURL, removeInvoice("AAA"))
+		require.Equal(t, wayforpaytest.ReasonOk, reasonCode(answer))
+		_, answer = post(t, srv.APIURL, removeInvoice("AAA"))
+		require.Equal(t, wayforpaytest.ReasonIllegalOrderState, reasonCode(answer))
+
+		order, _ := srv.Order("AAA")
+		require.Equal(t, wayforpaytest.StatusRemoved, order.Status)
+	})
+
+	t.Run("declined payment is not refunded", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+
+		post(t, srv.APIURL, createInvoice("AAA", "SALE"))
+		require.NoError(t, srv.Decline("AAA", 1101, "Declined To Card Issuer"))
+		_, answer := post(t, srv.APIURL, checkStatus("AAA"))
+		require.Equal(t, wayforpaytest.StatusDeclined, answer["transactionStatus"])
+		_, answer = post(t, srv.APIURL, refund("AAA", 100))
+		require.Equal(t, wayforpaytest.ReasonRefundNotAllowed, reasonCode(answer))
+	})
+}
+
+func TestServer_Scripted(t *testing.T) {
+	t.Run("FailNext leaves the order alone", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+		srv.AddOrder(wayforpaytest.Order{OrderReference: "AAA", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved})
+		srv.FailNext("REFUND", 1131, "Transaction in processing")
+
+		_, answer := post(t, srv.APIURL, refund("AAA", 100))
+		require.Equal(t, 1131, reasonCode(answer))
+		require.Equal(t, "AAA", answer["orderReference"])
+		order, _ := srv.Order("AAA")
+		require.Equal(t, wayforpaytest.StatusApproved, order.Status)
+
+		_, answer = post(t, srv.APIURL, refund("AAA", 100))
+		require.Equal(t, wayforpaytest.ReasonOk, reasonCode(answer))
+		require.Equal(t, 2, srv.Calls("REFUND"))
+	})
+
+	t.Run("FailNext does not skip signature checks", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+		srv.FailNext("CHECK_STATUS", 1131, "Transaction in processing")
+
+		req := checkStatus("AAA")
+		req["merchantSignature"] = "forged"
+		_, answer := post(t, srv.APIURL, req)
+		require.Equal(t, wayforpaytest.ReasonInvalidSignature, reasonCode(answer))
+	})
+
+	t.Run("DropNextResponse applies the request", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+		srv.AddOrder(wayforpaytest.Order{OrderReference: "AAA", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved})
+		srv.DropNextResponse("REFUND")
+
+		body, err := json.Marshal(refund("AAA", 100))
+		require.NoError(t, err)
+		_, err = http.Post(srv.APIURL, "application/json", bytes.NewReader(body))
+		require.Error(t, err)
+
+		order, _ := srv.Order("AAA")
+		require.Equal(t, wayforpaytest.StatusRefunded, order.Status)
+	})
+
+	t.Run("HoldNextRefund leaves the refund in processing", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+		srv.AddOrder(wayforpaytest.Order{OrderReference: "AAA", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved})
+		srv.HoldNextRefund()
+
+		_, answer := post(t, srv.APIURL, refund("AAA", 40))
+		require.Equal(t, wayforpaytest.ReasonRefundInProcessing, reasonCode(answer))
+		require.Equal(t, wayforpaytest.StatusRefundInProcessing, answer["transactionStatus"])
+		_, answer = post(t, srv.APIURL, checkStatus("AAA"))
+		require.Equal(t, wayforpaytest.StatusRefundInProcessing, answer["transactionStatus"])
+		require.Equal(t, 0.0, answer["refundAmount"])
+
+		_, answer = post(t, srv.APIURL, refund("AAA", 40))
+		require.Equal(t, wayforpaytest.ReasonRefundInProcessing, reasonCode(answer))
+
+		require.NoError(t, srv.FinishRefund("AAA"))
+		order, _ := srv.Order("AAA")
+		require.Equal(t, wayforpaytest.StatusApproved, order.Status)
+		require.Equal(t, 40.0, order.RefundedAmount)
+		require.Error(t, srv.FinishRefund("AAA"))
+	})
+
+	t.Run("ProcessNext applies the request", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+		srv.AddOrder(wayforpaytest.Order{OrderReference: "AAA", Amount: 100, Currency: "UAH", Status: wayforpaytest.StatusApproved})
+		srv.ProcessNext("REFUND", 1138, "Refund in processing")
+		srv.ProcessNext("REFUND", 1138, "Refund in processing")
+
+		_, answer := post(t, srv.APIURL, refund("AAA", 100))
+		require.Equal(t, 1138, reasonCode(answer))
+		require.Equal(t, "AAA", answer["orderReference"])
+		order, _ := srv.Order("AAA")
+		require.Equal(t, wayforpaytest.StatusRefunded, order.Status)
+
+		// The second refund fails on its own and gets its own answer.
+		_, answer = post(t, srv.APIURL, refund("AAA", 100))
+		require.Equal(t, wayforpaytest.ReasonRefundNotAllowed, reasonCode(answer))
+	})
+
+	t.Run("RespondNext answers as is", func(t *testing.T) {
+		srv := wayforpaytest.NewServer(merchantLogin, merchantSecret)
+		defer srv.Close()
+		srv.RespondNext("CHECK_STATUS", http.StatusBadGateway, "text/html", "<html>Bad Gateway</html>")
+
+		status, _ := post(t, srv.APIURL, checkStatus("AAA"))
+		require.Equal(t, http.StatusBadGateway, status)
+		_, answer := post(t, srv.APIURL, checkStatus("AAA"))
+		require.Equal(t, wayforpaytest.ReasonOrderNotFound, reasonCode(answer))
+		require.Equal(t, 2, srv.Calls("CHECK_STATUS"))
+	})
+}